@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/analyze"
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/handrecord"
+)
+
+// StartAnalyze reviews the decisions in a file of hand histories.
+func StartAnalyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	players := fs.String("players", "", "comma-separated players to review (default all)")
+	samples := fs.Int("samples", 50, "deals of the unseen cards each action is played out on")
+	threshold := fs.Float64("threshold", 20, "point loss from which a decision is a mistake")
+	seed := fs.Int64("seed", 1, "seed for the sampled deals")
+	jsonOut := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: tys analyze [flags] <hand history file>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("analyze takes one hand history file")
+	}
+	if *threshold <= 0 {
+		return fmt.Errorf("-threshold must be positive, got %g", *threshold)
+	}
+	path := fs.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	records, err := handrecord.Read(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	opts := analyze.Options{Samples: *samples, Threshold: *threshold, Seed: *seed, Log: os.Stderr}
+	for _, p := range strings.Split(*players, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			opts.Players = append(opts.Players, engine.PlayerID(p))
+		}
+	}
+	rep, err := analyze.Analyze(context.Background(), records, opts)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	}
+	return rep.WriteText(os.Stdout)
+}
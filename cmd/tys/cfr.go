@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ZygmuntJakub/1000/internal/cfr"
+)
+
+// StartCFR solves the auction and writes the strategy for the cfr bot.
+func StartCFR(args []string) error {
+	fs := flag.NewFlagSet("cfr", flag.ContinueOnError)
+	out := fs.String("out", "bids.json", "file to write the strategy to")
+	deals := fs.Int("deals", 5000, "pre-simulated deals")
+	iterations := fs.Int("iterations", 200000, "MCCFR iterations")
+	maxBid := fs.Int("max-bid", 300, "highest bid of the abstract auction")
+	evalDeals := fs.Int("eval-deals", 200, "duplicate deals against HeuristicBot after solving (0: skip)")
+	seed := fs.Int64("seed", 1, "seed for deals and sampling")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	t, err := cfr.Solve(ctx, cfr.Config{Deals: *deals, Iterations: *iterations, MaxBid: *maxBid, Seed: *seed}, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if *evalDeals > 0 {
+		margin, err := cfr.Evaluate(ctx, t, *evalDeals, *seed)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%+.1f points per deal vs heuristic\n", margin)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s; play it with the bot spec cfr:model=%s\n", *out, *out)
+	return nil
+}
@@ -1,19 +1,73 @@
 package main
 
 import (
+	"cmp"
+	"fmt"
 	"os"
 
 	"github.com/ZygmuntJakub/1000/internal/handler"
+	"github.com/ZygmuntJakub/1000/internal/rules"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 func main() {
 	if len(os.Args) > 1 && os.Args[1] == "simulation" {
-		StartSimulation()
+		if err := StartSimulation(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		return
 	}
-	h := handler.Handler{}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := StartReplay(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "tournament" {
+		if err := StartTournament(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "train" {
+		if err := StartTrain(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		if err := StartAnalyze(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cfr" {
+		if err := StartCFR(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "play" {
+		if err := StartPlay(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	// RULES names a built-in rules profile or a profile file
+	profile, err := rules.Load(cmp.Or(os.Getenv("RULES"), "PL standard"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	h := &handler.Handler{Profile: profile}
 
 	e := echo.New()
 
@@ -26,6 +80,9 @@ func main() {
 
 	e.POST("/player", h.AddPlayer)
 	e.GET("/player", h.ListPlayers)
+	e.GET("/bots", h.ListBots)
+	e.POST("/hint", h.Hint)
+	e.GET("/rules", h.Rules)
 
 	httpPort := os.Getenv("HTTP_PORT")
 	if httpPort == "" {
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/handrecord"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+	"github.com/ZygmuntJakub/1000/internal/rules"
+	"github.com/ZygmuntJakub/1000/internal/tui"
+)
+
+// StartPlay plays a match at the terminal between people and bots.
+func StartPlay(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	seats := fs.String("players", "", `comma-separated seats, each "human", "human:Name" or a bot spec (default: you against -bot)`)
+	botSpec := fs.String("bot", "heuristic", "opponent bot spec, e.g. ismcts:hard or heuristic:casual:delay=1s")
+	name := fs.String("name", "You", "your name at the table")
+	hintSpec := fs.String("hint", "ismcts:easy", "bot spec answering hints, or none")
+	plain := fs.Bool("plain", false, "scroll instead of redrawing the screen")
+	seed := fs.Int64("seed", 0, "seed for deals and bots (0: derived from the clock)")
+	savePath := fs.String("save", "", "save the match to this file after every hand and on quitting")
+	resume := fs.String("resume", "", "continue the match saved in this file, saving back to it")
+	rulesName := fs.String("rules", "PL standard", "rules profile of a new match: a built-in name or a profile file")
+	record := fs.String("record", "", "write the hand histories to this file, e.g. for tys analyze")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var save *tui.Save
+	if *resume != "" {
+		s, err := tui.ReadSave(*resume)
+		if err != nil {
+			return err
+		}
+		save = s
+		if *savePath == "" {
+			*savePath = *resume
+		}
+	} else {
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		specs := []string{"human:" + *name, *botSpec}
+		if *seats != "" {
+			specs = strings.Split(*seats, ",")
+		}
+		profile, err := rules.Load(*rulesName)
+		if err != nil {
+			return err
+		}
+		save = &tui.Save{Seats: specs, Seed: *seed, Params: profile.Params()}
+	}
+
+	screen := tui.NewScreen(os.Stdin, os.Stdout)
+	screen.Clear = !*plain
+	players, err := tui.NewSeats(save.Seats, screen, rand.New(rand.NewSource(save.Seed)).Int63())
+	if err != nil {
+		return err
+	}
+	ids := match.PlayerIDs(players...)
+	if save.Players == nil {
+		save.Players = ids
+	} else if !slices.Equal(ids, save.Players) {
+		return fmt.Errorf("saved players %v, seated %v", save.Players, ids)
+	}
+	defer func() {
+		for _, p := range players {
+			if c, ok := p.(interface{ Close() error }); ok {
+				c.Close()
+			}
+		}
+	}()
+	bots := map[engine.PlayerID]player.Player{}
+	for i, p := range players {
+		bots[ids[i]] = p
+		if h, ok := p.(*tui.Human); ok {
+			h.Hint = *hintSpec
+		}
+	}
+	write := func() error {
+		if *savePath == "" {
+			return nil
+		}
+		return save.Write(*savePath)
+	}
+	quit := func() error {
+		if *savePath == "" {
+			fmt.Printf("\nMatch abandoned. Seed=%d\n", save.Seed)
+			return nil
+		}
+		if err := write(); err != nil {
+			return err
+		}
+		fmt.Printf("\nMatch saved; continue with: tys play -resume %s\n", *savePath)
+		return nil
+	}
+	if *record != "" {
+		defer func() {
+			var done []*handrecord.Record
+			for _, r := range save.Hands {
+				if r.Cumulative != nil {
+					done = append(done, r)
+				}
+			}
+			if err := writeRecords(*record, done); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+
+	for {
+		if winner, over := save.Winner(); over {
+			fmt.Printf("%s wins the match!\n", winner)
+			return nil
+		}
+		g, err := save.Game()
+		if err != nil {
+			return err
+		}
+		screen.Hand = len(save.Hands)
+		err = match.PlayHand(g, bots)
+		save.Hands[len(save.Hands)-1].Update(g)
+		if errors.Is(err, tui.ErrQuit) {
+			return quit()
+		}
+		if err != nil {
+			return err
+		}
+		if err := write(); err != nil {
+			return err
+		}
+		if _, over := save.Winner(); over {
+			continue
+		}
+		if err := screen.Pause("Next hand"); errors.Is(err, tui.ErrQuit) {
+			return quit()
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ZygmuntJakub/1000/internal/replay"
+)
+
+// StartReplay re-runs a recorded action log and reports the first divergence.
+func StartReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: tys replay <action log file>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("replay takes one action log file")
+	}
+	path := fs.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	l, err := replay.Load(f)
+	if err != nil {
+		return fmt.Errorf("load %s: %w", path, err)
+	}
+	g, err := replay.Run(l)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Replayed %d steps without divergence. Phase=%v\n", len(l.Steps), g.Phase)
+	fmt.Printf("Deal points: %v\n", g.Scores.DealPoints)
+	fmt.Printf("Cumulative: %v\n", g.Scores.Cumulative)
+	return nil
+}
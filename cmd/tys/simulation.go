@@ -1,135 +1,101 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"math/rand"
+	"io"
+	"os"
 	"time"
 
-	"github.com/ZygmuntJakub/1000/internal/engine"
-	"github.com/ZygmuntJakub/1000/internal/player"
+	"github.com/ZygmuntJakub/1000/internal/handrecord"
+	"github.com/ZygmuntJakub/1000/internal/rules"
+	"github.com/ZygmuntJakub/1000/internal/simulation"
 )
 
-// makeDeck builds a deterministic 24-card deck matching engine tests order.
-func makeDeck() []engine.Card {
-	deck := make([]engine.Card, 0, 24)
-	for _, s := range []engine.Suit{engine.Spades, engine.Hearts, engine.Diamonds, engine.Clubs} {
-		for _, r := range []engine.Rank{engine.Nine, engine.Jack, engine.Queen, engine.King, engine.Ten, engine.Ace} {
-			deck = append(deck, engine.Card{Suit: s, Rank: r})
-		}
+// StartSimulation plays a batch of bot matches and prints their statistics.
+func StartSimulation(args []string) error {
+	fs := flag.NewFlagSet("simulation", flag.ContinueOnError)
+	bot1Spec := fs.String("bot1", "random", "first bot spec, e.g. heuristic:easy")
+	bot2Spec := fs.String("bot2", "heuristic", "second bot spec, e.g. ismcts:500ms")
+	matches := fs.Int("matches", 100, "matches to play; the bots swap seats every match")
+	seed := fs.Int64("seed", 0, "master seed for deals and bots (0: derived from the clock)")
+	parallel := fs.Int("parallel", 0, "matches played at once (0: GOMAXPROCS)")
+	maxHands := fs.Int("max-hands", 100, "hands before a match is stopped undecided")
+	rulesName := fs.String("rules", "PL standard", "rules profile: a built-in name or a profile file")
+	format := fs.String("format", "text", "output format: text, json or csv")
+	out := fs.String("out", "", "write the statistics to this file instead of stdout")
+	record := fs.String("record", "", "write the hand histories to this file, e.g. for tys analyze")
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
-	return deck
-}
-
-func shuffleDeck(deck []engine.Card) {
-	for i := range deck {
-		j := rand.Intn(i + 1)
-		deck[i], deck[j] = deck[j], deck[i]
+	if *format != "text" && *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown format %q", *format)
 	}
-}
-
-func StartSimulation() {
-	bot1 := player.NewRandomBot()
-	bot2 := player.NewRandomBot()
-	players := []engine.PlayerID{engine.PlayerID(bot1.Name()), engine.PlayerID(bot2.Name())}
-	playerToBot := map[engine.PlayerID]player.Player{
-		players[0]: bot1,
-		players[1]: bot2,
+	profile, err := rules.Load(*rulesName)
+	if err != nil {
+		return err
 	}
-	params := engine.GameParams{
-		Players:     players,
-		MinBid:      100,
-		MinRaise:    10,
-		HandCards:   10,
-		MusiksCount: 2,
-		MusikSize:   2,
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
 	}
-	var lastPlayPoints map[engine.PlayerID]int = nil
-	numberOfPlays := 0
-	for {
-		g := engine.NewGame(params, players[0], players, lastPlayPoints)
-		fmt.Printf("Phase=%v, Dealer=%s, Players=%v\n", g.Phase, g.Dealer, players)
-
-		// Deterministic deal (same as tests basic split):
-		deck := makeDeck()
-		shuffleDeck(deck)
-		h1 := append([]engine.Card{}, deck[:10]...)
-		h2 := append([]engine.Card{}, deck[10:20]...)
-		m1 := append([]engine.Card{}, deck[20:22]...)
-		m2 := append([]engine.Card{}, deck[22:24]...)
-		if err := g.SetDealtCards(map[engine.PlayerID][]engine.Card{players[0]: h1, players[1]: h2}, [][]engine.Card{m1, m2}); err != nil {
-			fmt.Printf("SetDealtCards error: %v\n", err)
-			return
-		}
-		fmt.Printf("Deal set. Phase=%v, Auction leader=%s\n", g.Phase, g.Auction.CurrentLeader)
-
-		for g.Phase == engine.PhaseAuction {
-			currentBot := playerToBot[g.Auction.CurrentLeader]
-			hand := append([]engine.Card{}, g.Deal.Hands[g.Auction.CurrentLeader]...)
-			bid, err := currentBot.MakeBidDecision(&hand, g.LegalBids(g.Auction.CurrentLeader))
-			if err != nil {
-				fmt.Printf("bid error: %v\n", err)
-				return
-			}
-			if err := g.PlaceBid(g.Auction.CurrentLeader, bid); err != nil {
-				fmt.Printf("bid error: %v\n", err)
-				return
-			}
-		}
-
-		fmt.Printf("Auction done. Declarer=%s, Value=%d Phase=%v\n", *g.Declarer, g.HighestBid(), g.Phase)
-
-		currentBot := playerToBot[*g.Declarer]
-		// Declarer chooses first musik, discard two smallest by rank (stable)
-		musikIndex, err := currentBot.ChooseMusik(len(g.Deal.Musiks))
-		if err != nil {
-			fmt.Printf("choose musik: %v\n", err)
-			return
-		}
-		if err := g.ChooseMusik(*g.Declarer, musikIndex); err != nil {
-			fmt.Printf("choose musik: %v\n", err)
-			return
-		}
-		// Choose two discard cards deterministically (lowest two ranks, by suit then rank)
-		hand := append([]engine.Card{}, g.Deal.Hands[*g.Declarer]...)
-		discards, err := currentBot.ChooseDiscardCards(&hand, 2)
-		if err != nil {
-			fmt.Printf("choose discard cards: %v\n", err)
-			return
-		}
-		if err := g.Discard(*g.Declarer, *discards); err != nil {
-			fmt.Printf("discard: %v\n", err)
-			return
+	// every source of randomness derives from the master seed, so a run is
+	// reproduced by passing the reported seed back in
+	report, err := simulation.Run(context.Background(), simulation.Config{
+		Bots:     [2]string{*bot1Spec, *bot2Spec},
+		Matches:  *matches,
+		Seed:     *seed,
+		Parallel: *parallel,
+		MaxHands: *maxHands,
+		Params:   profile.Params(),
+		Record:   *record != "",
+	})
+	if err != nil {
+		return err
+	}
+	if *record != "" {
+		if err := writeRecords(*record, report.Records); err != nil {
+			return err
 		}
-		fmt.Printf("Declarer took musik[0] and discarded %v, %v. Phase=%v\n", (*discards)[0], (*discards)[1], g.Phase)
+	}
 
-		// Auto-play the rest of the hand:
-		for g.Phase == engine.PhasePlay {
-			currentPlayer := g.CurrentTurnPlayer()
-			currentBot := playerToBot[currentPlayer]
-			legal := g.LegalPlays(currentPlayer)
+	if *out == "" {
+		return writeReport(os.Stdout, report, *format)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(f, report, *format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
 
-			cards, marriage, err := currentBot.PlayCard(&legal, &g.Play)
-			if err != nil {
-				fmt.Printf("play card: %v\n", err)
-				return
-			}
-			if err := g.PlayCard(currentPlayer, *cards, marriage); err != nil {
-				fmt.Printf("play card: %v\n", err)
-				return
-			}
-		}
+// writeReport writes report to w in format.
+func writeReport(w io.Writer, report *simulation.Report, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "csv":
+		return report.WriteCSV(w)
+	}
+	return report.WriteText(w)
+}
 
-		// Print final results
-		fmt.Printf("Hand finished. Phase=%v\n", g.Phase)
-		fmt.Printf("Deal points: %v\n", g.Scores.DealPoints)
-		fmt.Printf("Cumulative: %v\n", g.Scores.Cumulative)
-		if isWinning, player := g.IsWinningGame(); isWinning {
-			fmt.Printf("Player %s wins!\n", player)
-			fmt.Printf("Number of plays: %d\n", numberOfPlays)
-			return
-		}
-		lastPlayPoints = g.Scores.Cumulative
-		numberOfPlays++
-		time.Sleep(1 * time.Second)
+// writeRecords writes hand histories to path.
+func writeRecords(path string, records []*handrecord.Record) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := handrecord.Write(f, records...); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
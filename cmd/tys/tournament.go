@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/player"
+	"github.com/ZygmuntJakub/1000/internal/rules"
+	"github.com/ZygmuntJakub/1000/internal/tournament"
+)
+
+// StartTournament parses tournament flags, plays it and prints the standings.
+func StartTournament(args []string) error {
+	fs := flag.NewFlagSet("tournament", flag.ContinueOnError)
+	bots := fs.String("bots", "random,heuristic", "comma-separated bot specs, e.g. heuristic:easy,ismcts:500ms; repeat a spec to enter it twice")
+	format := fs.String("format", string(tournament.RoundRobin), "pairing format: round-robin or swiss")
+	games := fs.Int("games", 2, "games per pairing")
+	rounds := fs.Int("rounds", 0, "swiss rounds (0: log2 of the field)")
+	seed := fs.Int64("seed", 1, "master seed for every deal")
+	parallel := fs.Int("parallel", 0, "games played at once (0: GOMAXPROCS)")
+	timeout := fs.Duration("timeout", 10*time.Second, "time limit per bot decision")
+	maxHands := fs.Int("max-hands", 100, "hands before a game is drawn")
+	duplicate := fs.Bool("duplicate", false, "play every deal twice with seats swapped and compare points")
+	rulesName := fs.String("rules", "PL standard", "rules profile: a built-in name or a profile file")
+	jsonOut := fs.Bool("json", false, "print the full report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	profile, err := rules.Load(*rulesName)
+	if err != nil {
+		return err
+	}
+	cfg := tournament.Config{
+		Format:    tournament.Format(*format),
+		Games:     *games,
+		Rounds:    *rounds,
+		Seed:      *seed,
+		Parallel:  *parallel,
+		Timeout:   *timeout,
+		MaxHands:  *maxHands,
+		Duplicate: *duplicate,
+		Params:    profile.Params(),
+	}
+	count := map[string]int{}
+	for _, name := range strings.Split(*bots, ",") {
+		name = strings.TrimSpace(name)
+		factory, err := botFactory(name)
+		if err != nil {
+			return err
+		}
+		count[name]++
+		if count[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, count[name])
+		}
+		cfg.Entrants = append(cfg.Entrants, tournament.Entrant{Name: name, New: factory})
+	}
+
+	report, err := tournament.Run(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printStandings(report)
+	return nil
+}
+
+// botFactory resolves a bot spec, listing the registered types on error.
+func botFactory(spec string) (player.PlayerFactory, error) {
+	factory, err := player.FactoryFor(spec)
+	if err != nil {
+		var names []string
+		for _, t := range player.BotTypes() {
+			names = append(names, t.Name)
+		}
+		return nil, fmt.Errorf("%w (known: %s)", err, strings.Join(names, ", "))
+	}
+	return factory, nil
+}
+
+func printStandings(r *tournament.Report) {
+	unit := "games"
+	if len(r.Pairings) > 0 {
+		unit = "duplicate deals"
+	}
+	fmt.Printf("%s tournament, seed %d, %d %s\n\n", r.Format, r.Seed, len(r.Games), unit)
+	fmt.Printf("%-16s %5s %5s %5s %5s %7s %9s %9s %6s %15s\n",
+		"bot", "games", "won", "drawn", "lost", "win%", "pts/hand", "contract", "elo", "95% ci")
+	for _, s := range r.Standings {
+		fmt.Printf("%-16s %5d %5d %5d %5d %6.1f%% %9.1f %8.1f%% %6.0f %7.0f..%-6.0f\n",
+			s.Name, s.Games, s.Wins, s.Draws, s.Losses, 100*s.WinRate(), s.AvgPoints(),
+			100*s.ContractRate(), s.Rating, s.RatingLow, s.RatingHigh)
+	}
+	if len(r.Pairings) > 0 {
+		fmt.Printf("\nduplicate deals (points per deal, first minus second)\n")
+		for _, p := range r.Pairings {
+			fmt.Printf("%-16s vs %-16s %5d deals %+8.1f  95%% ci %+.1f..%+.1f\n",
+				p.Players[0], p.Players[1], p.Deals, p.Margin, p.MarginLow, p.MarginHigh)
+		}
+	}
+	for _, g := range r.Games {
+		if g.Forfeit != "" {
+			fmt.Printf("\nforfeit in %s vs %s (seed %d): %s", g.Players[0], g.Players[1], g.Seed, g.Forfeit)
+		}
+	}
+	if len(r.Offences) > 0 {
+		fmt.Printf("\n%d bot offences\n", len(r.Offences))
+	}
+}
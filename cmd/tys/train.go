@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ZygmuntJakub/1000/internal/nn"
+	"github.com/ZygmuntJakub/1000/internal/train"
+)
+
+// StartTrain trains a card-play network and writes it for the neural bot.
+func StartTrain(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	out := fs.String("out", "neural.json", "file to write the trained network to")
+	from := fs.String("from", "", "continue training this network file")
+	imitation := fs.Int("imitation", 20000, "hands imitating HeuristicBot")
+	selfPlay := fs.Int("self-play", 10000, "self-play hands")
+	batch := fs.Int("batch", 16, "hands per optimiser step")
+	lr := fs.Float64("lr", 1e-3, "learning rate")
+	evalEvery := fs.Int("eval-every", 5000, "hands between evaluations against HeuristicBot (0: never)")
+	evalDeals := fs.Int("eval-deals", 100, "duplicate deals per evaluation")
+	seed := fs.Int64("seed", 1, "seed for deals, sampling and initial weights")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg := train.Config{
+		ImitationHands: *imitation,
+		SelfPlayHands:  *selfPlay,
+		BatchHands:     *batch,
+		LearningRate:   *lr,
+		EvalEvery:      *evalEvery,
+		EvalDeals:      *evalDeals,
+		Seed:           *seed,
+	}
+	if *from != "" {
+		f, err := os.Open(*from)
+		if err != nil {
+			return err
+		}
+		cfg.Net, err = nn.Load(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("load %s: %w", *from, err)
+		}
+	}
+	net, err := train.Train(context.Background(), cfg, os.Stdout)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := net.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s; play it with the bot spec neural:model=%s\n", *out, *out)
+	return nil
+}
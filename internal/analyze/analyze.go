@@ -0,0 +1,222 @@
+// Package analyze reviews recorded hands for mistakes.
+//
+// Every decision of the reviewed players is re-evaluated from their own view
+// of the game, so a player is never blamed for not knowing hidden cards: the
+// unseen cards are dealt at random many times and each legal action is
+// played out on the same deals (see hint.Evaluate). A decision is a mistake
+// when the best action averages at least Threshold points more than the
+// one taken.
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/handrecord"
+	"github.com/ZygmuntJakub/1000/internal/hint"
+)
+
+// Options configures Analyze. Zero values get the defaults noted.
+type Options struct {
+	// Players lists the players to review (default all).
+	Players []engine.PlayerID
+	// Samples is the number of deals each action is played out on (default 50).
+	Samples int
+	// Candidates caps the actions evaluated per decision (default 12); the
+	// action taken is always among them.
+	Candidates int
+	// Threshold is the point loss from which a decision is a mistake. It
+	// must be positive, as every loss is at least 0; zero selects the
+	// default 20.
+	Threshold float64
+	Seed      int64
+	// Log, if set, receives a line per analysed hand.
+	Log io.Writer
+}
+
+// Decision is one reviewed decision.
+type Decision struct {
+	// Hand numbers the record from 1.
+	Hand   int             `json:"hand"`
+	Player engine.PlayerID `json:"player"`
+	Phase  engine.Phase    `json:"phase"`
+	// Trick numbers the trick from 1 during play.
+	Trick  int          `json:"trick,omitempty"`
+	Played hint.Outcome `json:"played"`
+	Best   hint.Outcome `json:"best"`
+	// Loss is how many points Best averages over Played.
+	Loss    float64 `json:"loss"`
+	Mistake bool    `json:"mistake"`
+}
+
+// Hand summarises a reviewed record.
+type Hand struct {
+	Dealer     engine.PlayerID         `json:"dealer"`
+	Declarer   engine.PlayerID         `json:"declarer,omitempty"`
+	Bid        int                     `json:"bid,omitempty"`
+	Made       bool                    `json:"made"`
+	DealPoints map[engine.PlayerID]int `json:"dealPoints"`
+}
+
+// Summary totals one player's decisions.
+type Summary struct {
+	Player    engine.PlayerID `json:"player"`
+	Decisions int             `json:"decisions"`
+	Mistakes  int             `json:"mistakes"`
+	// Loss adds up the points lost in mistakes.
+	Loss float64 `json:"loss"`
+}
+
+// Report is the result of Analyze.
+type Report struct {
+	Samples   int     `json:"samples"`
+	Threshold float64 `json:"threshold"`
+	Hands     []Hand  `json:"hands"`
+	// Decisions holds every reviewed decision with more than one legal
+	// action, in play order.
+	Decisions []Decision `json:"decisions"`
+	Summary   []Summary  `json:"summary"`
+}
+
+// Analyze reviews records in order.
+func Analyze(ctx context.Context, records []*handrecord.Record, opts Options) (*Report, error) {
+	if opts.Samples <= 0 {
+		opts.Samples = 50
+	}
+	if opts.Candidates <= 0 {
+		opts.Candidates = 12
+	}
+	if opts.Threshold < 0 {
+		return nil, fmt.Errorf("negative mistake threshold %g", opts.Threshold)
+	}
+	if opts.Threshold == 0 {
+		opts.Threshold = 20
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	rep := &Report{Samples: opts.Samples, Threshold: opts.Threshold}
+	summaries := map[engine.PlayerID]*Summary{}
+	var order []engine.PlayerID
+	for i, r := range records {
+		g, err := r.Start()
+		if err != nil {
+			return nil, fmt.Errorf("hand %d: %w", i+1, err)
+		}
+		for _, a := range r.Actions() {
+			if g.ActingPlayer() != a.Player {
+				return nil, fmt.Errorf("hand %d: %v by %s out of turn", i+1, a, a.Player)
+			}
+			if reviewed(opts.Players, a.Player) {
+				d, err := review(ctx, g, a, opts, rng)
+				if err != nil {
+					return nil, fmt.Errorf("hand %d: %v by %s: %w", i+1, a, a.Player, err)
+				}
+				if d != nil {
+					d.Hand = i + 1
+					rep.Decisions = append(rep.Decisions, *d)
+					s := summaries[a.Player]
+					if s == nil {
+						s = &Summary{Player: a.Player}
+						summaries[a.Player] = s
+						order = append(order, a.Player)
+					}
+					s.Decisions++
+					if d.Mistake {
+						s.Mistakes++
+						s.Loss += d.Loss
+					}
+				}
+			}
+			if err := g.Apply(a); err != nil {
+				return nil, fmt.Errorf("hand %d: %v by %s: %w", i+1, a, a.Player, err)
+			}
+		}
+		h := Hand{Dealer: g.Dealer, DealPoints: g.Scores.DealPoints}
+		if g.Declarer != nil {
+			h.Declarer, h.Bid = *g.Declarer, g.HighestBid()
+			h.Made = g.Scores.DealPoints[h.Declarer] >= h.Bid
+		}
+		rep.Hands = append(rep.Hands, h)
+		if opts.Log != nil {
+			fmt.Fprintf(opts.Log, "analysed hand %d/%d\n", i+1, len(records))
+		}
+	}
+	for _, p := range order {
+		rep.Summary = append(rep.Summary, *summaries[p])
+	}
+	return rep, nil
+}
+
+func reviewed(players []engine.PlayerID, p engine.PlayerID) bool {
+	return len(players) == 0 || slices.Contains(players, p)
+}
+
+// review evaluates a, taken in g, against the alternatives. It returns nil
+// when a was the only legal action.
+func review(ctx context.Context, g *engine.GameState, a engine.Action, opts Options, rng *rand.Rand) (*Decision, error) {
+	legal := g.LegalActions()
+	if len(legal) < 2 {
+		return nil, nil
+	}
+	view := g.View(a.Player)
+	outcomes, err := hint.Evaluate(ctx, view, hint.Candidates(view, legal, a, opts.Candidates), opts.Samples, rng)
+	if err != nil {
+		return nil, err
+	}
+	d := &Decision{Player: a.Player, Phase: g.Phase, Played: outcomes[0], Best: outcomes[0]}
+	if g.Phase == engine.PhasePlay {
+		d.Trick = len(g.Play.CompletedTricks) + 1
+	}
+	for _, o := range outcomes[1:] {
+		if o.ExpectedPoints > d.Best.ExpectedPoints {
+			d.Best = o
+		}
+	}
+	d.Loss = d.Best.ExpectedPoints - d.Played.ExpectedPoints
+	d.Mistake = d.Loss >= opts.Threshold
+	return d, nil
+}
+
+// WriteText writes the mistakes hand by hand, then the per-player summary.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%d hands, %d decisions reviewed; each action played out on %d deals; mistakes cost %.0f+ points\n",
+		len(r.Hands), len(r.Decisions), r.Samples, r.Threshold)
+	for i, h := range r.Hands {
+		fmt.Fprintf(tw, "\nHand %d: %s deals", i+1, h.Dealer)
+		if h.Declarer != "" {
+			result := "makes it"
+			if !h.Made {
+				result = "goes down"
+			}
+			fmt.Fprintf(tw, "; %s declares %d and %s with %d", h.Declarer, h.Bid, result, h.DealPoints[h.Declarer])
+		}
+		fmt.Fprintln(tw)
+		clean := true
+		for _, d := range r.Decisions {
+			if d.Hand != i+1 || !d.Mistake {
+				continue
+			}
+			clean = false
+			where := d.Phase.String()
+			if d.Trick > 0 {
+				where = fmt.Sprintf("trick %d", d.Trick)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t-%.0f\tbetter: %s (%+.0f vs %+.0f, wins %.0f%% vs %.0f%%)\n",
+				where, d.Player, d.Played.Description, d.Loss, d.Best.Description,
+				d.Best.ExpectedPoints, d.Played.ExpectedPoints, 100*d.Best.WinProbability, 100*d.Played.WinProbability)
+		}
+		if clean {
+			fmt.Fprintln(tw, "  no mistakes")
+		}
+	}
+	fmt.Fprintln(tw, "\nplayer\tdecisions\tmistakes\tpoints lost")
+	for _, s := range r.Summary {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.0f\n", s.Player, s.Decisions, s.Mistakes, s.Loss)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,80 @@
+package analyze
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/handrecord"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// records plays n hands of RandomBot (P1) against HeuristicBot (P2).
+func records(t *testing.T, n int) []*handrecord.Record {
+	t.Helper()
+	players := []engine.PlayerID{"P1", "P2"}
+	rng := rand.New(rand.NewSource(11))
+	var out []*handrecord.Record
+	for i := range n {
+		g := engine.NewGame(engine.GameParams{}, players[i%2], players, nil)
+		if err := match.Deal(g, rng); err != nil {
+			t.Fatal(err)
+		}
+		r := handrecord.New(g)
+		bots := map[engine.PlayerID]player.Player{"P1": player.NewRandomBot(int64(i)), "P2": player.NewHeuristicBot()}
+		if err := match.PlayHand(g, bots); err != nil {
+			t.Fatal(err)
+		}
+		r.Update(g)
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestAnalyzeFlagsRandomPlay(t *testing.T) {
+	recs := records(t, 3)
+	rep, err := Analyze(context.Background(), recs, Options{Players: []engine.PlayerID{"P1"}, Samples: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Hands) != 3 || len(rep.Summary) != 1 || rep.Summary[0].Player != "P1" {
+		t.Fatalf("hands %d, summary %+v", len(rep.Hands), rep.Summary)
+	}
+	mistakes := 0
+	for _, d := range rep.Decisions {
+		if d.Player != "P1" {
+			t.Fatalf("reviewed %s", d.Player)
+		}
+		if d.Loss < 0 || d.Mistake != (d.Loss >= rep.Threshold) {
+			t.Fatalf("inconsistent decision %+v", d)
+		}
+		if d.Mistake {
+			mistakes++
+		}
+	}
+	if mistakes == 0 || rep.Summary[0].Mistakes != mistakes {
+		t.Fatalf("%d mistakes flagged, summary says %d", mistakes, rep.Summary[0].Mistakes)
+	}
+
+	var text bytes.Buffer
+	if err := rep.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "better: ") {
+		t.Errorf("text report lists no mistakes:\n%s", text.String())
+	}
+	if _, err := json.Marshal(rep); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzeRejectsNegativeThreshold(t *testing.T) {
+	if _, err := Analyze(context.Background(), records(t, 1), Options{Threshold: -1}); err == nil {
+		t.Fatal("analysed with a negative threshold")
+	}
+}
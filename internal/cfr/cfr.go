@@ -0,0 +1,250 @@
+// Package cfr solves the auction for player.CFRBot with Monte Carlo
+// counterfactual regret minimisation.
+//
+// The abstract game starts after the deal: the dealer holds the automatic
+// MinBid and the players alternate between passing and raising by MinRaise
+// up to MaxBid, the only moves the engine offers. Decisions are keyed by
+// player.BidKey, so hands are bucketed by player.EstimateHandValue. Chance
+// picks a deal from a pool of pre-simulated deals, each played out by
+// HeuristicBot once with either player declaring; those playouts fix the
+// declarer's deal points and the defender's score whatever the bid. The
+// musiks are face down, so the musik choice is part of that chance outcome
+// rather than a decision, and the engine has no contract raise after it.
+package cfr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+	"github.com/ZygmuntJakub/1000/internal/tournament"
+)
+
+// Config describes a solver run. Zero values get the defaults noted.
+type Config struct {
+	// Deals is the number of pre-simulated deals (default 5000).
+	Deals int
+	// Iterations is the number of MCCFR iterations, each sampling one deal
+	// and traversing it for both players (default 200000).
+	Iterations int
+	// MaxBid caps the abstract auction (default 300).
+	MaxBid int
+	Seed   int64
+}
+
+// deal is one pre-simulated deal. Index 0 is the dealer, 1 the other player.
+type deal struct {
+	bucket [2]int
+	// points[i] is the declarer's deal points when i declares and
+	// defence[i] the defender's score for that hand.
+	points, defence [2]int
+}
+
+// payoff returns the score difference in favour of declarer i at bid v.
+func (d *deal) payoff(i, bid int) int {
+	if d.points[i] >= bid {
+		return bid - d.defence[i]
+	}
+	return -bid - d.defence[i]
+}
+
+// node accumulates regrets and the average strategy of one decision; index
+// 0 is passing and 1 raising. Regrets are floored at zero and later
+// iterations weigh more in the average (as in CFR+), which converges much
+// faster on the long raising lines.
+type node struct {
+	regret, sum [2]float64
+}
+
+// strategy returns the regret-matching strategy of n.
+func (n *node) strategy() [2]float64 {
+	r0, r1 := n.regret[0], n.regret[1]
+	if r0+r1 == 0 {
+		return [2]float64{0.5, 0.5}
+	}
+	return [2]float64{r0 / (r0 + r1), r1 / (r0 + r1)}
+}
+
+type solver struct {
+	minBid, minRaise, maxBid int
+	deals                    []deal
+	nodes                    map[string]*node
+	rng                      *rand.Rand
+	// iteration weighs contributions to the average strategy.
+	iteration float64
+}
+
+// Solve simulates the deal pool, runs MCCFR and returns the average
+// strategy. Progress is written to log.
+func Solve(ctx context.Context, cfg Config, log io.Writer) (*player.BidTable, error) {
+	if cfg.Deals == 0 {
+		cfg.Deals = 5000
+	}
+	if cfg.Iterations == 0 {
+		cfg.Iterations = 200000
+	}
+	if cfg.MaxBid == 0 {
+		cfg.MaxBid = 300
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	s := newSolver(engine.GameParams{}, cfg.MaxBid, rng)
+	for i := range cfg.Deals {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		d, err := simulate(rng.Int63())
+		if err != nil {
+			return nil, fmt.Errorf("deal %d: %w", i+1, err)
+		}
+		s.deals = append(s.deals, d)
+		if (i+1)%(max(cfg.Deals/10, 1)) == 0 {
+			fmt.Fprintf(log, "simulated %d/%d deals\n", i+1, cfg.Deals)
+		}
+	}
+	for it := range cfg.Iterations {
+		if it%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		s.iterate()
+		if (it+1)%(max(cfg.Iterations/10, 1)) == 0 {
+			fmt.Fprintf(log, "iteration %d/%d: %d decisions\n", it+1, cfg.Iterations, len(s.nodes))
+		}
+	}
+	t := s.table()
+	t.Iterations, t.Deals = cfg.Iterations, cfg.Deals
+	return t, nil
+}
+
+func newSolver(params engine.GameParams, maxBid int, rng *rand.Rand) *solver {
+	g := engine.NewGame(params, "P1", []engine.PlayerID{"P1", "P2"}, nil)
+	return &solver{
+		minBid:   g.Params.MinBid,
+		minRaise: g.Params.MinRaise,
+		maxBid:   maxBid,
+		nodes:    map[string]*node{},
+		rng:      rng,
+	}
+}
+
+// iterate samples a deal and traverses it once for each player.
+func (s *solver) iterate() {
+	s.iteration++
+	d := &s.deals[s.rng.Intn(len(s.deals))]
+	for traverser := range 2 {
+		s.walk(d, s.minBid, 1, traverser)
+	}
+}
+
+// walk returns the value for traverser of the auction where toAct faces the
+// bid high. It updates regrets at traverser's decisions and samples the
+// other player's, adding their strategy to the average (external sampling).
+func (s *solver) walk(d *deal, high, toAct, traverser int) float64 {
+	pass := func() float64 {
+		declarer := 1 - toAct
+		u := float64(d.payoff(declarer, high))
+		if traverser != declarer {
+			u = -u
+		}
+		return u
+	}
+	if high+s.minRaise > s.maxBid {
+		return pass()
+	}
+	key := player.BidKey(toAct == 0, d.bucket[toAct], high)
+	n := s.nodes[key]
+	if n == nil {
+		n = &node{}
+		s.nodes[key] = n
+	}
+	sigma := n.strategy()
+	if toAct != traverser {
+		n.sum[0] += s.iteration * sigma[0]
+		n.sum[1] += s.iteration * sigma[1]
+		if s.rng.Float64() < sigma[1] {
+			return s.walk(d, high+s.minRaise, 1-toAct, traverser)
+		}
+		return pass()
+	}
+	u := [2]float64{pass(), s.walk(d, high+s.minRaise, 1-toAct, traverser)}
+	value := sigma[0]*u[0] + sigma[1]*u[1]
+	n.regret[0] = max(n.regret[0]+u[0]-value, 0)
+	n.regret[1] = max(n.regret[1]+u[1]-value, 0)
+	return value
+}
+
+// table returns the average strategy.
+func (s *solver) table() *player.BidTable {
+	t := &player.BidTable{MaxBid: s.maxBid, Raise: map[string]float64{}}
+	for key, n := range s.nodes {
+		if total := n.sum[0] + n.sum[1]; total > 0 {
+			t.Raise[key] = n.sum[1] / total
+		}
+	}
+	return t
+}
+
+// simulate deals a deck shuffled from seed and plays it out with either
+// player declaring.
+func simulate(seed int64) (deal, error) {
+	deck := match.NewDeck()
+	match.Shuffle(deck, rand.New(rand.NewSource(seed)))
+	players := []engine.PlayerID{"P1", "P2"}
+	var d deal
+	for i := range 2 {
+		g := engine.NewGame(engine.GameParams{}, players[0], players, nil)
+		if err := match.DealDeck(g, deck); err != nil {
+			return deal{}, err
+		}
+		if i == 0 {
+			d.bucket = [2]int{player.BidBucket(g.Deal.Hands[players[0]]), player.BidBucket(g.Deal.Hands[players[1]])}
+		}
+		// The playout is the same at any bid, so bid just enough for i to
+		// declare.
+		if i == 1 {
+			if err := g.PlaceBid(players[1], g.Params.MinBid+g.Params.MinRaise); err != nil {
+				return deal{}, err
+			}
+		}
+		if err := g.PlaceBid(players[1-i], 0); err != nil {
+			return deal{}, err
+		}
+		bots := map[engine.PlayerID]player.Player{players[0]: player.NewHeuristicBot(), players[1]: player.NewHeuristicBot()}
+		if err := match.PlayHand(g, bots); err != nil {
+			return deal{}, err
+		}
+		d.points[i] = g.Scores.DealPoints[players[i]]
+		d.defence[i] = g.Scores.Cumulative[players[1-i]]
+	}
+	return d, nil
+}
+
+// Evaluate plays deals duplicate deals of CFRBot with t against HeuristicBot
+// and returns the mean points per deal in CFRBot's favour.
+func Evaluate(ctx context.Context, t *player.BidTable, deals int, seed int64) (float64, error) {
+	report, err := tournament.Run(ctx, tournament.Config{
+		Entrants: []tournament.Entrant{
+			{Name: "cfr", New: func(int64) player.Player { return &player.CFRBot{Table: t} }},
+			{Name: "heuristic", New: func(int64) player.Player { return player.NewHeuristicBot() }},
+		},
+		Games:     deals,
+		Seed:      seed,
+		Duplicate: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(report.Pairings) == 0 {
+		return 0, fmt.Errorf("no duplicate deal finished without a forfeit (%d offences)", len(report.Offences))
+	}
+	p := report.Pairings[0]
+	if p.Players[0] != "cfr" {
+		return -p.Margin, nil
+	}
+	return p.Margin, nil
+}
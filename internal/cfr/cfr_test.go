@@ -0,0 +1,79 @@
+package cfr
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+func TestSolverFindsTheLastMakeableBid(t *testing.T) {
+	// Either player makes exactly 250, so whoever may bid 250 declares.
+	// Raises alternate from 110 for the non-dealer, who can therefore outbid
+	// the dealer up to 250: the non-dealer always raises and the dealer
+	// gives up at once.
+	s := newSolver(engine.GameParams{}, 300, rand.New(rand.NewSource(1)))
+	s.deals = []deal{{bucket: [2]int{150, 150}, points: [2]int{250, 250}}}
+	for range 20000 {
+		s.iterate()
+	}
+	tab := s.table()
+	if p := tab.Raise[player.BidKey(false, 150, 100)]; p < 0.9 {
+		t.Errorf("non-dealer raises 100 with probability %.2f", p)
+	}
+	if p := tab.Raise[player.BidKey(true, 150, 110)]; p > 0.1 {
+		t.Errorf("dealer raises 110 with probability %.2f", p)
+	}
+	// Off the equilibrium path the average is barely sampled, but regrets
+	// still settle: raising past 250 loses.
+	if p := s.nodes[player.BidKey(true, 150, 250)].strategy()[1]; p > 0.1 {
+		t.Errorf("dealer would raise 250 with probability %.2f", p)
+	}
+}
+
+func TestSolveProducesPlayableTable(t *testing.T) {
+	tab, err := Solve(context.Background(), Config{Deals: 50, Iterations: 5000, Seed: 3}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tab.Raise) == 0 {
+		t.Fatal("empty strategy")
+	}
+	for key, p := range tab.Raise {
+		if p < 0 || p > 1 {
+			t.Fatalf("%s: probability %v", key, p)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := tab.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	tab, err = player.ReadBidTable(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := []engine.PlayerID{"P1", "P2"}
+	rng := rand.New(rand.NewSource(4))
+	for range 20 {
+		g := engine.NewGame(engine.GameParams{}, players[0], players, nil)
+		if err := match.Deal(g, rng); err != nil {
+			t.Fatal(err)
+		}
+		bots := map[engine.PlayerID]player.Player{
+			"P1": &player.CFRBot{Table: tab},
+			"P2": &player.CFRBot{Table: tab, Sample: true},
+		}
+		if err := match.PlayHand(g, bots); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := (&player.CFRBot{Table: tab}).MakeBidDecision(engine.PlayerView{Phase: engine.PhaseAuction}); err == nil {
+		t.Error("bid without legal bids")
+	}
+}
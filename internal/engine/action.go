@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ActionType identifies the kind of an Action.
+type ActionType string
+
+const (
+	ActionDeal        ActionType = "deal"
+	ActionBid         ActionType = "bid"
+	ActionChooseMusik ActionType = "choose_musik"
+	ActionDiscard     ActionType = "discard"
+	ActionPlayCard    ActionType = "play_card"
+)
+
+// Action is a single engine step that can be stored and applied later.
+type Action struct {
+	Type   ActionType
+	Player PlayerID `json:",omitempty"`
+	// Deal
+	Hands  map[PlayerID][]Card `json:",omitempty"`
+	Musiks [][]Card            `json:",omitempty"`
+	// Bid: 0 means pass
+	Value int `json:",omitempty"`
+	// ChooseMusik
+	Index int `json:",omitempty"`
+	// Discard
+	Cards []Card `json:",omitempty"`
+	// PlayCard
+	Card     Card
+	Marriage bool `json:",omitempty"`
+}
+
+// MarshalJSON leaves Card out of actions other than play_card, where the
+// zero card would read as a nine of spades.
+func (a Action) MarshalJSON() ([]byte, error) {
+	type fields Action // drops the method
+	out := struct {
+		fields
+		Card *Card `json:",omitempty"`
+	}{fields: fields(a)}
+	if a.Type == ActionPlayCard {
+		out.Card = &a.Card
+	}
+	return json.Marshal(out)
+}
+
+// Apply performs the action through the matching engine method. Card slices
+// are copied so the action can be reused after the game mutates its state.
+func (g *GameState) Apply(a Action) error {
+	switch a.Type {
+	case ActionDeal:
+		hands := make(map[PlayerID][]Card, len(a.Hands))
+		for p, h := range a.Hands {
+			hands[p] = append([]Card{}, h...)
+		}
+		musiks := make([][]Card, len(a.Musiks))
+		for i, m := range a.Musiks {
+			musiks[i] = append([]Card{}, m...)
+		}
+		return g.SetDealtCards(hands, musiks)
+	case ActionBid:
+		return g.PlaceBid(a.Player, a.Value)
+	case ActionChooseMusik:
+		return g.ChooseMusik(a.Player, a.Index)
+	case ActionDiscard:
+		return g.Discard(a.Player, append([]Card{}, a.Cards...))
+	case ActionPlayCard:
+		return g.PlayCard(a.Player, a.Card, a.Marriage)
+	default:
+		return fmt.Errorf("unknown action %q", a.Type)
+	}
+}
+
+// Clone returns a deep copy of the game that shares no memory with g.
+func (g *GameState) Clone() *GameState {
+	c := *g
+	c.Params.Players = append([]PlayerID(nil), g.Params.Players...)
+	if g.Declarer != nil {
+		d := *g.Declarer
+		c.Declarer = &d
+	}
+	if g.Deal.Hands != nil {
+		c.Deal.Hands = make(map[PlayerID][]Card, len(g.Deal.Hands))
+		for p, h := range g.Deal.Hands {
+			c.Deal.Hands[p] = append([]Card(nil), h...)
+		}
+	}
+	if g.Deal.Musiks != nil {
+		c.Deal.Musiks = make([][]Card, len(g.Deal.Musiks))
+		for i, m := range g.Deal.Musiks {
+			c.Deal.Musiks[i] = append([]Card(nil), m...)
+		}
+	}
+	c.Deal.TableCards = append([]Card(nil), g.Deal.TableCards...)
+	c.Auction.Bids = append([]AuctionBid(nil), g.Auction.Bids...)
+	c.Auction.ActivePlayers = append([]PlayerID(nil), g.Auction.ActivePlayers...)
+	c.Play.CurrentTrick = cloneTrick(g.Play.CurrentTrick)
+	if g.Play.CompletedTricks != nil {
+		c.Play.CompletedTricks = make([]Trick, len(g.Play.CompletedTricks))
+		for i, t := range g.Play.CompletedTricks {
+			c.Play.CompletedTricks[i] = cloneTrick(t)
+		}
+	}
+	c.Play.Trump = cloneSuit(g.Play.Trump)
+	if g.Play.LastTrickWinner != nil {
+		w := *g.Play.LastTrickWinner
+		c.Play.LastTrickWinner = &w
+	}
+	c.Scores.DealPoints = cloneScores(g.Scores.DealPoints)
+	c.Scores.Cumulative = cloneScores(g.Scores.Cumulative)
+	return &c
+}
+
+func cloneTrick(t Trick) Trick {
+	c := t
+	c.LedSuit = cloneSuit(t.LedSuit)
+	if t.Plays != nil {
+		c.Plays = make([]Play, len(t.Plays))
+		for i, p := range t.Plays {
+			c.Plays[i] = p
+			c.Plays[i].AnnouncedMarriage = cloneSuit(p.AnnouncedMarriage)
+		}
+	}
+	return c
+}
+
+func cloneSuit(s *Suit) *Suit {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+func cloneScores(m map[PlayerID]int) map[PlayerID]int {
+	if m == nil {
+		return nil
+	}
+	out := make(map[PlayerID]int, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// ActingPlayer returns the player expected to act next, or "" when the
+// game is not waiting for a player.
+func (g *GameState) ActingPlayer() PlayerID {
+	switch g.Phase {
+	case PhaseAuction:
+		return g.Auction.CurrentLeader
+	case PhaseTalonExchange:
+		if g.Declarer != nil {
+			return *g.Declarer
+		}
+	case PhasePlay:
+		return g.CurrentTurnPlayer()
+	}
+	return ""
+}
+
+// LegalActions lists the actions the acting player may take. Bids are
+// limited to those returned by LegalBids and discards to every pair of
+// cards in the declarer's hand.
+func (g *GameState) LegalActions() []Action {
+	p := g.ActingPlayer()
+	if p == "" {
+		return nil
+	}
+	var out []Action
+	switch g.Phase {
+	case PhaseAuction:
+		for _, v := range g.LegalBids(p) {
+			out = append(out, Action{Type: ActionBid, Player: p, Value: v})
+		}
+	case PhaseTalonExchange:
+		if g.Deal.Musiks != nil {
+			for i := range g.Deal.Musiks {
+				out = append(out, Action{Type: ActionChooseMusik, Player: p, Index: i})
+			}
+			return out
+		}
+		hand := g.Deal.Hands[p]
+		for i := range hand {
+			for j := i + 1; j < len(hand); j++ {
+				out = append(out, Action{Type: ActionDiscard, Player: p, Cards: []Card{hand[i], hand[j]}})
+			}
+		}
+	case PhasePlay:
+		leading := len(g.Play.CurrentTrick.Plays) == 0 && g.marriageAllowed()
+		hand := g.Deal.Hands[p]
+		for _, c := range g.LegalPlays(p) {
+			out = append(out, Action{Type: ActionPlayCard, Player: p, Card: c})
+			if leading && (c.Rank == King || c.Rank == Queen) && holdsOtherKQ(hand, c) {
+				out = append(out, Action{Type: ActionPlayCard, Player: p, Card: c, Marriage: true})
+			}
+		}
+	}
+	return out
+}
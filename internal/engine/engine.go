@@ -141,11 +141,65 @@ func (g *GameState) PlaceBid(player PlayerID, value int) error {
 	if value < g.Params.MinBid || value <= high || value-high < g.Params.MinRaise {
 		return fmt.Errorf("illegal bid")
 	}
+	if limit := g.bidLimit(player); limit > 0 && value > limit {
+		return fmt.Errorf("bid %d is over the limit of %d", value, limit)
+	}
 	g.Auction.Bids = append(g.Auction.Bids, AuctionBid{Player: player, Value: value})
 	g.Auction.CurrentLeader = nextPlayer(g.Params.Players, turn)
 	return nil
 }
 
+// bidLimit returns the highest bid the rules allow player, or 0 for no limit.
+func (g *GameState) bidLimit(player PlayerID) int {
+	limit := g.Params.MaxBid
+	if bare := g.Params.MaxBidWithoutMarriage; bare > 0 && (limit == 0 || bare < limit) && !holdsMarriage(g.Deal.Hands[player]) {
+		limit = bare
+	}
+	return limit
+}
+
+func holdsMarriage(hand []Card) bool {
+	for _, c := range hand {
+		if c.Rank == King && holdsOtherKQ(hand, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedealDue reports whether the rules have the cards dealt again: the
+// auction has not started and a player's hand is one that calls for a
+// redeal.
+func (g *GameState) RedealDue() bool {
+	if g.Phase != PhaseAuction || len(g.Auction.Bids) > 1 {
+		return false
+	}
+	for _, p := range g.Params.Players {
+		nines, points := 0, 0
+		for _, c := range g.Deal.Hands[p] {
+			if c.Rank == Nine {
+				nines++
+			}
+			points += PointsFor(c.Rank)
+		}
+		if (g.Params.RedealFourNines && nines == 4) || points < g.Params.RedealBelow {
+			return true
+		}
+	}
+	return false
+}
+
+// Redeal takes the cards back before the auction starts, returning the game
+// to the deal phase.
+func (g *GameState) Redeal() error {
+	if g.Phase != PhaseAuction || len(g.Auction.Bids) > 1 {
+		return PhaseError("not before the first bid")
+	}
+	g.Deal = DealState{}
+	g.Phase = PhaseDeal
+	return nil
+}
+
 func removePlayer(xs []PlayerID, x PlayerID) []PlayerID {
 	out := make([]PlayerID, 0, len(xs))
 	for _, v := range xs {
@@ -227,17 +281,20 @@ func (g *GameState) PlayCard(player PlayerID, card Card, announceMarriage bool)
 		return fmt.Errorf("card not in hand")
 	}
 	if len(g.Play.CurrentTrick.Plays) == 0 {
+		// validate before touching the trick so a rejected lead leaves no trace
+		if announceMarriage && !((card.Rank == King || card.Rank == Queen) && holdsOtherKQ(hand, card)) {
+			return fmt.Errorf("invalid marriage announcement")
+		}
+		if announceMarriage && !g.marriageAllowed() {
+			return fmt.Errorf("no marriage may be announced on the first trick")
+		}
 		g.Play.CurrentTrick.LedSuit = &card.Suit
 		g.Play.CurrentTrick.Leader = player
 		if announceMarriage {
-			if (card.Rank == King || card.Rank == Queen) && holdsOtherKQ(hand, card) {
-				s := card.Suit
-				g.Play.Trump = &s
-				g.Scores.DealPoints[player] += MarriageValue(s)
-				g.Play.CurrentTrick.Plays = append(g.Play.CurrentTrick.Plays, Play{Player: player, Card: card, AnnouncedMarriage: &s})
-			} else {
-				return fmt.Errorf("invalid marriage announcement")
-			}
+			s := card.Suit
+			g.Play.Trump = &s
+			g.Scores.DealPoints[player] += MarriageValue(s)
+			g.Play.CurrentTrick.Plays = append(g.Play.CurrentTrick.Plays, Play{Player: player, Card: card, AnnouncedMarriage: &s})
 		} else {
 			g.Play.CurrentTrick.Plays = append(g.Play.CurrentTrick.Plays, Play{Player: player, Card: card})
 		}
@@ -283,6 +340,12 @@ func (g *GameState) CurrentTurnPlayer() PlayerID {
 	return g.Params.Players[idx]
 }
 
+// marriageAllowed reports whether the rules allow announcing a marriage on
+// the current lead.
+func (g *GameState) marriageAllowed() bool {
+	return !g.Params.NoMarriageOnFirstTrick || len(g.Play.CompletedTricks) > 0
+}
+
 func holdsOtherKQ(hand []Card, played Card) bool {
 	needRank := King
 	if played.Rank == King {
@@ -364,9 +427,8 @@ func firstCardBetter(a, b Card, led Suit, trump *Suit) bool {
 }
 
 func firstCardRankGreater(a, b Rank) bool {
-	order := []Rank{Nine, Jack, Queen, King, Ten, Ace}
 	idx := func(r Rank) int {
-		for i, v := range order {
+		for i, v := range RankOrder {
 			if v == r {
 				return i
 			}
@@ -419,12 +481,25 @@ func (g *GameState) FinalizeScoring() error {
 		if p == declarer {
 			continue
 		}
-		g.Scores.Cumulative[p] += g.Scores.DealPoints[p]
+		g.Scores.Cumulative[p] = g.defend(g.Scores.Cumulative[p], g.Scores.DealPoints[p])
 	}
 	g.Phase = PhaseHandEnd
 	return nil
 }
 
+// defend returns a defender's cumulative score after adding the points of
+// their deal, rounded and stopped at the barrel as the rules say.
+func (g *GameState) defend(cumulative, points int) int {
+	if r := g.Params.ScoreRounding; r > 0 {
+		points = (points + r/2) / r * r
+	}
+	barrel := g.Params.BarrelAt
+	if barrel == 0 || cumulative+points <= barrel {
+		return cumulative + points
+	}
+	return max(cumulative, barrel)
+}
+
 // CurrentLeader returns the player who leads the current trick.
 func (g *GameState) CurrentLeader() PlayerID { return g.Play.CurrentTrick.Leader }
 
@@ -444,6 +519,9 @@ func (g *GameState) LegalBids(player PlayerID) []int {
 	if high > 0 {
 		next = high + g.Params.MinRaise
 	}
+	if limit := g.bidLimit(player); limit > 0 && next > limit {
+		return []int{0}
+	}
 	return []int{0, next}
 }
 
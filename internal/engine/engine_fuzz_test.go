@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// phaseSuccessors lists the phases an accepted action may move the game to.
+var phaseSuccessors = map[Phase][]Phase{
+	PhaseDeal:          {PhaseAuction},
+	PhaseAuction:       {PhaseAuction, PhaseTalonExchange},
+	PhaseTalonExchange: {PhaseTalonExchange, PhasePlay},
+	PhasePlay:          {PhasePlay, PhaseHandEnd},
+}
+
+// invariantChecker plays random legal hands and verifies engine invariants
+// after every action.
+type invariantChecker struct {
+	t   *testing.T
+	rng *rand.Rand
+	g   *GameState
+	// cumulative scores when the hand started
+	start map[PlayerID]int
+}
+
+func (c *invariantChecker) apply(a Action) {
+	c.t.Helper()
+	for _, legal := range c.g.LegalActions() {
+		if err := c.g.Clone().Apply(legal); err != nil {
+			c.t.Fatalf("LegalActions offered %+v, engine rejected it: %v", legal, err)
+		}
+	}
+	before := c.g.Phase
+	if err := c.g.Apply(a); err != nil {
+		c.t.Fatalf("legal %s by %s rejected: %v", a.Type, a.Player, err)
+	}
+	if !containsPhase(phaseSuccessors[before], c.g.Phase) {
+		c.t.Fatalf("illegal phase transition %v -> %v after %s", before, c.g.Phase, a.Type)
+	}
+	c.checkState()
+}
+
+// reject verifies the engine refuses a and leaves the state untouched.
+func (c *invariantChecker) reject(a Action) {
+	c.t.Helper()
+	before := c.g.Clone()
+	if err := c.g.Apply(a); err == nil {
+		c.t.Fatalf("illegal %s by %s accepted: %+v", a.Type, a.Player, a)
+	}
+	if !reflect.DeepEqual(before, c.g.Clone()) {
+		c.t.Fatalf("rejected %s by %s mutated state", a.Type, a.Player)
+	}
+}
+
+func containsPhase(phases []Phase, p Phase) bool {
+	for _, v := range phases {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *invariantChecker) checkState() {
+	c.t.Helper()
+	g := c.g
+	if err := g.Validate(); err != nil {
+		c.t.Fatalf("%v phase: %v", g.Phase, err)
+	}
+	seen := map[Card]int{}
+	inHands := 0
+	for _, h := range g.Deal.Hands {
+		for _, card := range h {
+			seen[card]++
+		}
+		inHands += len(h)
+	}
+	for _, m := range g.Deal.Musiks {
+		for _, card := range m {
+			seen[card]++
+		}
+	}
+	for _, card := range g.Deal.TableCards {
+		seen[card]++
+	}
+	for _, t := range append(g.Play.CompletedTricks, g.Play.CurrentTrick) {
+		for _, p := range t.Plays {
+			seen[p.Card]++
+		}
+	}
+	if len(seen) != 24 {
+		c.t.Fatalf("expected 24 distinct cards, found %d in %v phase", len(seen), g.Phase)
+	}
+	for card, n := range seen {
+		if n != 1 {
+			c.t.Fatalf("card %v appears %d times", card, n)
+		}
+	}
+	// RemainingCards only drops when a trick completes
+	if g.Phase == PhasePlay && g.Play.RemainingCards != inHands+len(g.Play.CurrentTrick.Plays) {
+		c.t.Fatalf("remaining cards %d, hands and current trick hold %d", g.Play.RemainingCards, inHands+len(g.Play.CurrentTrick.Plays))
+	}
+	if g.Phase != PhaseHandEnd {
+		for p, v := range g.Scores.Cumulative {
+			if v != c.start[p] {
+				c.t.Fatalf("cumulative score of %s changed mid-hand", p)
+			}
+		}
+		return
+	}
+
+	marriages := map[PlayerID]int{}
+	for _, t := range g.Play.CompletedTricks {
+		for _, p := range t.Plays {
+			if p.AnnouncedMarriage != nil {
+				marriages[p.Player] += MarriageValue(*p.AnnouncedMarriage)
+			}
+		}
+	}
+	trickPoints := 0
+	for _, p := range g.Params.Players {
+		trickPoints += g.Scores.DealPoints[p] - marriages[p]
+	}
+	if trickPoints != 120 {
+		c.t.Fatalf("trick points sum to %d, want 120", trickPoints)
+	}
+	declarer := *g.Declarer
+	for _, p := range g.Params.Players {
+		want := c.start[p] + g.Scores.DealPoints[p]
+		if p == declarer {
+			want = c.start[p] - g.HighestBid()
+			if g.Scores.DealPoints[p] >= g.HighestBid() {
+				want = c.start[p] + g.HighestBid()
+			}
+		}
+		if g.Scores.Cumulative[p] != want {
+			c.t.Fatalf("cumulative score of %s is %d, want %d", p, g.Scores.Cumulative[p], want)
+		}
+	}
+}
+
+// checkLegalPlays verifies that PlayCard accepts exactly the cards LegalPlays returns.
+func (c *invariantChecker) checkLegalPlays(p PlayerID) {
+	c.t.Helper()
+	legal := map[Card]bool{}
+	for _, card := range c.g.LegalPlays(p) {
+		legal[card] = true
+	}
+	for _, card := range c.g.Deal.Hands[p] {
+		err := c.g.Clone().PlayCard(p, card, false)
+		if legal[card] != (err == nil) {
+			c.t.Fatalf("LegalPlays says %v for %v, PlayCard returned %v", legal[card], card, err)
+		}
+	}
+}
+
+func (c *invariantChecker) playHand() {
+	c.t.Helper()
+	g := c.g
+	deck := makeDeck()
+	c.rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	p0, p1 := g.Params.Players[0], g.Params.Players[1]
+	c.apply(Action{Type: ActionDeal, Hands: map[PlayerID][]Card{p0: deck[:10], p1: deck[10:20]}, Musiks: [][]Card{deck[20:22], deck[22:24]}})
+
+	for g.Phase == PhaseAuction {
+		p := g.Auction.CurrentLeader
+		c.reject(Action{Type: ActionBid, Player: nextPlayer(g.Params.Players, p), Value: 0})
+		c.reject(Action{Type: ActionBid, Player: p, Value: g.HighestBid()})
+		bids := g.LegalBids(p)
+		bid := bids[c.rng.Intn(len(bids))]
+		if bid != 0 && c.rng.Intn(4) == 0 {
+			bid += g.Params.MinRaise * c.rng.Intn(3)
+		}
+		c.apply(Action{Type: ActionBid, Player: p, Value: bid})
+	}
+
+	declarer := *g.Declarer
+	c.reject(Action{Type: ActionChooseMusik, Player: nextPlayer(g.Params.Players, declarer)})
+	c.reject(Action{Type: ActionChooseMusik, Player: declarer, Index: len(g.Deal.Musiks)})
+	c.apply(Action{Type: ActionChooseMusik, Player: declarer, Index: c.rng.Intn(len(g.Deal.Musiks))})
+	hand := g.Deal.Hands[declarer]
+	c.reject(Action{Type: ActionDiscard, Player: declarer, Cards: hand[:1]})
+	i := c.rng.Intn(len(hand))
+	j := (i + 1 + c.rng.Intn(len(hand)-1)) % len(hand)
+	c.apply(Action{Type: ActionDiscard, Player: declarer, Cards: []Card{hand[i], hand[j]}})
+
+	for g.Phase == PhasePlay {
+		p := g.CurrentTurnPlayer()
+		c.checkLegalPlays(p)
+		for _, card := range g.Deal.Hands[p] {
+			if len(g.Play.CurrentTrick.Plays) == 0 && (card.Rank == Nine || !holdsOtherKQ(g.Deal.Hands[p], card)) {
+				c.reject(Action{Type: ActionPlayCard, Player: p, Card: card, Marriage: true})
+			}
+		}
+		if other := nextPlayer(g.Params.Players, p); len(g.Deal.Hands[other]) > 0 {
+			c.reject(Action{Type: ActionPlayCard, Player: other, Card: g.Deal.Hands[other][0]})
+		}
+		legal := g.LegalPlays(p)
+		card := legal[c.rng.Intn(len(legal))]
+		marriage := len(g.Play.CurrentTrick.Plays) == 0 &&
+			(card.Rank == King || card.Rank == Queen) &&
+			holdsOtherKQ(g.Deal.Hands[p], card) && c.rng.Intn(3) > 0
+		c.apply(Action{Type: ActionPlayCard, Player: p, Card: card, Marriage: marriage})
+	}
+}
+
+// playRandomMatch plays hands with rotating dealers until someone wins or
+// the hand limit is reached.
+func playRandomMatch(t *testing.T, seed int64, hands int) {
+	rng := rand.New(rand.NewSource(seed))
+	players := []PlayerID{"P1", "P2"}
+	var cumulative map[PlayerID]int
+	dealer := players[rng.Intn(2)]
+	for range hands {
+		g := NewGame(GameParams{Players: players}, dealer, players, cumulative)
+		c := &invariantChecker{t: t, rng: rng, g: g, start: cloneScores(g.Scores.Cumulative)}
+		c.playHand()
+		if won, _ := g.IsWinningGame(); won {
+			return
+		}
+		cumulative = g.Scores.Cumulative
+		dealer = nextPlayer(players, dealer)
+	}
+}
+
+func TestRandomMatchesKeepInvariants(t *testing.T) {
+	for seed := int64(0); seed < 25; seed++ {
+		playRandomMatch(t, seed, 30)
+	}
+}
+
+func FuzzRandomMatch(f *testing.F) {
+	for _, seed := range []int64{0, 1, 42, 1000, -7} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		playRandomMatch(t, seed, 30)
+	})
+}
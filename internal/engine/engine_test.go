@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -740,3 +742,258 @@ func TestMarriageSetsTrumpAndScores(t *testing.T) {
 		t.Fatalf("expected marriage points added")
 	}
 }
+
+func TestValidate(t *testing.T) {
+	// newPlayedGame returns a game after P2 won the auction and led one trick.
+	newPlayedGame := func(t *testing.T) *GameState {
+		players := []PlayerID{"P1", "P2"}
+		g := NewGame(GameParams{Players: players}, players[0], players, nil)
+		deck := makeDeck()
+		if err := g.SetDealtCards(map[PlayerID][]Card{"P1": append([]Card{}, deck[:10]...), "P2": append([]Card{}, deck[10:20]...)}, [][]Card{{deck[20], deck[21]}, {deck[22], deck[23]}}); err != nil {
+			t.Fatalf("deal: %v", err)
+		}
+		if err := g.Validate(); err != nil {
+			t.Fatalf("auction: %v", err)
+		}
+		_ = g.PlaceBid("P2", 110)
+		_ = g.PlaceBid("P1", 0)
+		_ = g.ChooseMusik("P2", 0)
+		if err := g.Validate(); err != nil {
+			t.Fatalf("talon exchange: %v", err)
+		}
+		_ = g.Discard("P2", []Card{g.Deal.Hands["P2"][0], g.Deal.Hands["P2"][1]})
+		lead := g.LegalPlays("P2")[0]
+		_ = g.PlayCard("P2", lead, false)
+		_ = g.PlayCard("P1", g.LegalPlays("P1")[0], false)
+		if err := g.Validate(); err != nil {
+			t.Fatalf("play: %v", err)
+		}
+		return g
+	}
+	cases := []struct {
+		name    string
+		corrupt func(g *GameState)
+		want    string
+	}{
+		{
+			name:    "duplicate card across hands",
+			corrupt: func(g *GameState) { g.Deal.Hands["P1"][0] = g.Deal.Hands["P2"][0] },
+			want:    "in both hand of P1 and hand of P2",
+		},
+		{
+			name:    "declarer disagrees with auction",
+			corrupt: func(g *GameState) { d := PlayerID("P1"); g.Declarer = &d },
+			want:    "declarer is P1, auction was won by P2",
+		},
+		{
+			name:    "remaining cards out of sync",
+			corrupt: func(g *GameState) { g.Play.RemainingCards = 20 },
+			want:    "remaining cards 20",
+		},
+		{
+			name:    "trick won by the wrong play",
+			corrupt: func(g *GameState) { g.Play.CompletedTricks[0].WinningPlayIndex ^= 1 },
+			want:    "trick 1 won by play",
+		},
+		{
+			name:    "musiks left on the table",
+			corrupt: func(g *GameState) { g.Deal.Musiks = [][]Card{} },
+			want:    "musiks still on the table",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := newPlayedGame(t)
+			c.corrupt(g)
+			err := g.Validate()
+			if err == nil {
+				t.Fatalf("expected validation error")
+			}
+			if !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("expected %q in %v", c.want, err)
+			}
+		})
+	}
+
+	t.Run("reports all violations", func(t *testing.T) {
+		g := newPlayedGame(t)
+		g.Play.RemainingCards = 3
+		g.Scores.DealPoints["P1"] = 999
+		verr, ok := g.Validate().(ValidationError)
+		if !ok || len(verr) != 2 {
+			t.Fatalf("expected two violations, got %v", verr)
+		}
+	})
+}
+
+func TestViewAndEventsAreRedacted(t *testing.T) {
+	players := []PlayerID{"P1", "P2"}
+	g := NewGame(GameParams{Players: players}, players[0], players, nil)
+	deck := makeDeck()
+	deal := Action{Type: ActionDeal, Hands: map[PlayerID][]Card{"P1": deck[:10], "P2": deck[10:20]}, Musiks: [][]Card{deck[20:22], deck[22:24]}}
+	ev, err := g.Step(deal)
+	if err != nil {
+		t.Fatalf("deal: %v", err)
+	}
+	if seen := ev.For("P1"); len(seen.Action.Hands) != 1 || seen.Action.Musiks != nil {
+		t.Fatalf("deal event leaks hidden cards: %+v", seen.Action)
+	}
+	v := g.View("P2")
+	if len(v.Hand) != 10 || v.HandSizes["P1"] != 10 || v.MusikCount != 2 {
+		t.Fatalf("unexpected auction view: %+v", v)
+	}
+	if len(v.LegalBids) != 2 || g.View("P1").LegalBids != nil {
+		t.Fatalf("only the bidder should see legal bids")
+	}
+	_ = g.PlaceBid("P2", 110)
+	_ = g.PlaceBid("P1", 0)
+	_ = g.ChooseMusik("P2", 1)
+	discard := []Card{g.Deal.Hands["P2"][0], g.Deal.Hands["P2"][1]}
+	ev, err = g.Step(Action{Type: ActionDiscard, Player: "P2", Cards: discard})
+	if err != nil {
+		t.Fatalf("discard: %v", err)
+	}
+	if ev.For("P1").Action.Cards != nil || len(ev.For("P2").Action.Cards) != 2 {
+		t.Fatalf("discards must only be shown to the declarer")
+	}
+	if d := g.View("P2").OwnDiscards; len(d) != 2 || d[0] != discard[0] {
+		t.Fatalf("declarer should see own discards, got %v", d)
+	}
+	if g.View("P1").OwnDiscards != nil {
+		t.Fatalf("defender must not see discards")
+	}
+	v = g.View("P2")
+	v.Hand[0] = Card{}
+	if g.Deal.Hands["P2"][0] == (Card{}) {
+		t.Fatalf("view must not share memory with the game")
+	}
+	g.Deal.TableCards = g.Deal.TableCards[:1]
+	if d := g.View("P2").OwnDiscards; len(d) != 0 {
+		t.Fatalf("short table cards gave discards %v", d)
+	}
+}
+
+func TestVariantAuctionAndMarriage(t *testing.T) {
+	players := []PlayerID{"P1", "P2"}
+	g := NewGame(GameParams{Players: players, MaxBidWithoutMarriage: 120, NoMarriageOnFirstTrick: true}, players[0], players, nil)
+	deck := makeDeck()
+	// P1 holds the spade marriage; P2's kings are in the musiks
+	h1 := deck[:10]
+	h2 := []Card{deck[10], deck[11], deck[12], deck[13], deck[14], deck[16], deck[17], deck[18], deck[19], deck[20]}
+	if err := g.SetDealtCards(map[PlayerID][]Card{"P1": h1, "P2": h2}, [][]Card{{deck[15], deck[21]}, {deck[22], deck[23]}}); err != nil {
+		t.Fatalf("SetDealtCards: %v", err)
+	}
+	if err := g.PlaceBid("P2", 120); err != nil {
+		t.Fatalf("bid at the limit: %v", err)
+	}
+	if err := g.PlaceBid("P1", 130); err != nil {
+		t.Fatalf("bid over the limit with a marriage: %v", err)
+	}
+	if bids := g.LegalBids("P2"); len(bids) != 1 || bids[0] != 0 {
+		t.Fatalf("legal bids without a marriage %v, want only a pass", bids)
+	}
+	if err := g.PlaceBid("P2", 140); err == nil {
+		t.Fatalf("bid over the limit without a marriage accepted")
+	}
+	if err := g.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	_ = g.PlaceBid("P2", 0)
+	_ = g.ChooseMusik("P1", 0)
+	if err := g.Discard("P1", []Card{deck[6], deck[7]}); err != nil {
+		t.Fatalf("discard: %v", err)
+	}
+	if err := g.PlayCard("P1", Card{Spades, Queen}, true); err == nil {
+		t.Fatalf("marriage announced on the first trick")
+	}
+	for _, a := range g.LegalActions() {
+		if a.Marriage {
+			t.Fatalf("legal actions offer %v on the first trick", a)
+		}
+	}
+	if err := g.PlayCard("P1", Card{Spades, Ace}, false); err != nil {
+		t.Fatalf("lead: %v", err)
+	}
+	if err := g.PlayCard("P2", Card{Diamonds, Nine}, false); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if err := g.PlayCard("P1", Card{Spades, Queen}, true); err != nil {
+		t.Fatalf("marriage after taking a trick: %v", err)
+	}
+	if err := g.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestVariantSettlement(t *testing.T) {
+	players := []PlayerID{"P1", "P2"}
+	for _, tc := range []struct {
+		start, points, want int
+	}{
+		{0, 45, 50},
+		{850, 44, 880},
+		{885, 20, 885},
+	} {
+		g := NewGame(GameParams{Players: players, ScoreRounding: 10, BarrelAt: 880}, players[0], players, map[PlayerID]int{"P2": tc.start})
+		dec := PlayerID("P1")
+		g.Declarer = &dec
+		g.Scores.DealPoints["P1"] = 120 - tc.points
+		g.Scores.DealPoints["P2"] = tc.points
+		g.Phase = PhaseScoring
+		if err := g.FinalizeScoring(); err != nil {
+			t.Fatalf("FinalizeScoring: %v", err)
+		}
+		if got := g.Scores.Cumulative["P2"]; got != tc.want {
+			t.Errorf("defender at %d taking %d: cumulative %d, want %d", tc.start, tc.points, got, tc.want)
+		}
+	}
+}
+
+func TestRedeal(t *testing.T) {
+	players := []PlayerID{"P1", "P2"}
+	g := NewGame(GameParams{Players: players, RedealFourNines: true}, players[0], players, nil)
+	deck := makeDeck()
+	nines := []Card{deck[0], deck[6], deck[12], deck[18]}
+	h1 := append(append([]Card{}, nines...), deck[1], deck[2], deck[3], deck[4], deck[5], deck[7])
+	h2 := []Card{deck[8], deck[9], deck[10], deck[11], deck[13], deck[14], deck[15], deck[16], deck[17], deck[19]}
+	musiks := [][]Card{{deck[20], deck[21]}, {deck[22], deck[23]}}
+	if err := g.SetDealtCards(map[PlayerID][]Card{"P1": h1, "P2": h2}, musiks); err != nil {
+		t.Fatalf("SetDealtCards: %v", err)
+	}
+	if !g.RedealDue() {
+		t.Fatalf("four nines not redealt")
+	}
+	if err := g.Redeal(); err != nil || g.Phase != PhaseDeal {
+		t.Fatalf("Redeal: %v in %v phase", err, g.Phase)
+	}
+	h1[0], h2[0] = h2[0], h1[0]
+	if err := g.SetDealtCards(map[PlayerID][]Card{"P1": h1, "P2": h2}, musiks); err != nil {
+		t.Fatalf("SetDealtCards: %v", err)
+	}
+	if g.RedealDue() {
+		t.Fatalf("redeal due with the nines split")
+	}
+}
+
+func TestActionJSONOmitsUnusedCard(t *testing.T) {
+	for _, a := range []Action{
+		{Type: ActionBid, Player: "P2", Value: 110},
+		{Type: ActionPlayCard, Player: "P2", Card: Card{Hearts, Queen}, Marriage: true},
+		{Type: ActionPlayCard, Player: "P1", Card: Card{Spades, Nine}},
+	} {
+		b, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(b), `"Card"`); got != (a.Type == ActionPlayCard) {
+			t.Errorf("%s marshals as %s", a.Type, b)
+		}
+		var back Action
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatal(err)
+		}
+		if back.Type != a.Type || back.Card != a.Card || back.Value != a.Value || back.Marriage != a.Marriage {
+			t.Errorf("%s round-trips to %+v", b, back)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+// Event reports an accepted action to the players.
+type Event struct {
+	Action Action
+	// Trick is set when the action completed a trick.
+	Trick *Trick `json:",omitempty"`
+	// Phase is the phase after the action.
+	Phase Phase
+}
+
+// Step applies a and returns the event describing it.
+func (g *GameState) Step(a Action) (Event, error) {
+	tricks := len(g.Play.CompletedTricks)
+	if err := g.Apply(a); err != nil {
+		return Event{}, err
+	}
+	ev := Event{Action: a, Phase: g.Phase}
+	if len(g.Play.CompletedTricks) > tricks {
+		t := cloneTrick(g.Play.CompletedTricks[len(g.Play.CompletedTricks)-1])
+		ev.Trick = &t
+	}
+	return ev, nil
+}
+
+// For redacts the event for player p: only p's own hand is revealed from
+// the deal and discards are revealed only to the declarer.
+func (e Event) For(p PlayerID) Event {
+	out := e
+	switch e.Action.Type {
+	case ActionDeal:
+		out.Action.Hands = map[PlayerID][]Card{p: append([]Card(nil), e.Action.Hands[p]...)}
+		out.Action.Musiks = nil
+	case ActionDiscard:
+		if e.Action.Player != p {
+			out.Action.Cards = nil
+		} else {
+			out.Action.Cards = append([]Card(nil), e.Action.Cards...)
+		}
+	}
+	if e.Trick != nil {
+		t := cloneTrick(*e.Trick)
+		out.Trick = &t
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+var suitLetters = map[Suit]string{Spades: "S", Clubs: "C", Diamonds: "D", Hearts: "H"}
+
+var rankCodes = map[Rank]string{Nine: "9", Jack: "J", Queen: "Q", King: "K", Ten: "10", Ace: "A"}
+
+// Letter returns the ASCII letter used for the suit in card codes (S, C, D, H).
+func (s Suit) Letter() string { return suitLetters[s] }
+
+// Code returns the ASCII card code, rank followed by suit letter, e.g. "10H" or "QS".
+func (c Card) Code() string { return rankCodes[c.Rank] + suitLetters[c.Suit] }
+
+// ParseSuit parses a suit letter as returned by Suit.Letter.
+func ParseSuit(s string) (Suit, error) {
+	for suit, l := range suitLetters {
+		if strings.EqualFold(s, l) {
+			return suit, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid suit %q", s)
+}
+
+// ParseCard parses a card code as returned by Card.Code.
+func ParseCard(s string) (Card, error) {
+	if len(s) < 2 {
+		return Card{}, fmt.Errorf("invalid card %q", s)
+	}
+	suit, err := ParseSuit(s[len(s)-1:])
+	if err != nil {
+		return Card{}, fmt.Errorf("invalid card %q", s)
+	}
+	for rank, code := range rankCodes {
+		if strings.EqualFold(s[:len(s)-1], code) {
+			return Card{Suit: suit, Rank: rank}, nil
+		}
+	}
+	return Card{}, fmt.Errorf("invalid card %q", s)
+}
+
+// ParseCards parses whitespace-separated card codes.
+func ParseCards(s string) ([]Card, error) {
+	var out []Card
+	for _, f := range strings.Fields(s) {
+		c, err := ParseCard(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// FormatCards joins card codes with single spaces.
+func FormatCards(cards []Card) string {
+	codes := make([]string, len(cards))
+	for i, c := range cards {
+		codes[i] = c.Code()
+	}
+	return strings.Join(codes, " ")
+}
+
+// MarshalText encodes the card as its code, so JSON carries "10H" rather
+// than numeric suit and rank.
+func (c Card) MarshalText() ([]byte, error) {
+	if _, ok := rankCodes[c.Rank]; !ok || suitLetters[c.Suit] == "" {
+		return nil, fmt.Errorf("invalid card %d/%d", c.Suit, c.Rank)
+	}
+	return []byte(c.Code()), nil
+}
+
+// UnmarshalText decodes a card code.
+func (c *Card) UnmarshalText(b []byte) error {
+	card, err := ParseCard(string(b))
+	if err != nil {
+		return err
+	}
+	*c = card
+	return nil
+}
+
+// MarshalText encodes the suit as its letter.
+func (s Suit) MarshalText() ([]byte, error) {
+	if suitLetters[s] == "" {
+		return nil, fmt.Errorf("invalid suit %d", s)
+	}
+	return []byte(s.Letter()), nil
+}
+
+// UnmarshalText decodes a suit letter.
+func (s *Suit) UnmarshalText(b []byte) error {
+	suit, err := ParseSuit(string(b))
+	if err != nil {
+		return err
+	}
+	*s = suit
+	return nil
+}
+
+// MarshalText encodes the phase by name, e.g. "talon exchange".
+func (p Phase) MarshalText() ([]byte, error) {
+	if p < PhaseInit || p > PhaseHandEnd {
+		return nil, fmt.Errorf("invalid phase %d", p)
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a phase name.
+func (p *Phase) UnmarshalText(b []byte) error {
+	for ph := PhaseInit; ph <= PhaseHandEnd; ph++ {
+		if ph.String() == string(b) {
+			*p = ph
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid phase %q", b)
+}
+
+// String describes the action for people, e.g. "bid 120", "pass",
+// "take musik 2", "discard 9D JD" or "play KH announcing the marriage".
+func (a Action) String() string {
+	switch a.Type {
+	case ActionBid:
+		if a.Value == 0 {
+			return "pass"
+		}
+		return fmt.Sprintf("bid %d", a.Value)
+	case ActionChooseMusik:
+		return fmt.Sprintf("take musik %d", a.Index+1)
+	case ActionDiscard:
+		return "discard " + FormatCards(a.Cards)
+	case ActionPlayCard:
+		if a.Marriage {
+			return "play " + a.Card.Code() + " announcing the marriage"
+		}
+		return "play " + a.Card.Code()
+	}
+	return string(a.Type)
+}
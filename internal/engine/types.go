@@ -24,6 +24,12 @@ const (
 	Ace               // A
 )
 
+// Suits lists the suits in order of their marriage value.
+var Suits = []Suit{Spades, Clubs, Diamonds, Hearts}
+
+// RankOrder lists the ranks from lowest to highest trick-taking power.
+var RankOrder = []Rank{Nine, Jack, Queen, King, Ten, Ace}
+
 // Card represents a playing card.
 type Card struct {
 	Suit Suit
@@ -141,6 +147,31 @@ type GameParams struct {
 	MusiksCount   int
 	MusikSize     int
 	MaxGamePoints int
+
+	// The variant options below play the rules of docs/rules.md when zero.
+
+	// MaxBid, if set, caps every bid.
+	MaxBid int
+	// MaxBidWithoutMarriage, if set, caps the bids of a player who holds no
+	// marriage.
+	MaxBidWithoutMarriage int
+	// NoMarriageOnFirstTrick forbids announcing a marriage on the first
+	// trick of the deal, so only a player who has taken a trick can.
+	NoMarriageOnFirstTrick bool
+	// ScoreRounding, if set, rounds the defenders' deal points to the
+	// nearest multiple of it, halves up, before they are added to their
+	// cumulative scores.
+	ScoreRounding int
+	// RedealFourNines has the cards dealt again when a player is dealt all
+	// four nines.
+	RedealFourNines bool
+	// RedealBelow, if set, has the cards dealt again when a player's hand
+	// is worth fewer card points.
+	RedealBelow int
+	// BarrelAt, if set, is the barrel: defending never takes a cumulative
+	// score above it or raises one already there, so a player on the
+	// barrel can only go out by making a contract.
+	BarrelAt int
 }
 
 // GameState is the root state container.
@@ -0,0 +1,344 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError lists every inconsistency found by Validate.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid game state: " + strings.Join(e, "; ")
+}
+
+// Validate checks that the state is internally consistent: every card is in
+// exactly one place, the auction, tricks and turn order agree with history,
+// and phase-specific fields are present. It returns a ValidationError listing
+// all violations, or nil.
+func (g *GameState) Validate() error {
+	v := &validator{g: g}
+	v.checkParams()
+	if len(v.errs) == 0 {
+		v.checkCards()
+		v.checkAuction()
+		v.checkPhaseFields()
+		v.checkTricks()
+	}
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type validator struct {
+	g    *GameState
+	errs ValidationError
+}
+
+func (v *validator) addf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) isPlayer(p PlayerID) bool {
+	for _, x := range v.g.Params.Players {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) checkParams() {
+	g := v.g
+	if g.Phase < PhaseInit || g.Phase > PhaseHandEnd {
+		v.addf("unknown phase %d", g.Phase)
+	}
+	if len(g.Params.Players) != 2 {
+		v.addf("expected 2 players, got %d", len(g.Params.Players))
+	}
+	seen := map[PlayerID]bool{}
+	for _, p := range g.Params.Players {
+		if seen[p] {
+			v.addf("duplicate player %s", p)
+		}
+		seen[p] = true
+	}
+	if !v.isPlayer(g.Dealer) {
+		v.addf("dealer %s is not a player", g.Dealer)
+	}
+	if g.Declarer != nil && !v.isPlayer(*g.Declarer) {
+		v.addf("declarer %s is not a player", *g.Declarer)
+	}
+}
+
+func (v *validator) checkCards() {
+	g := v.g
+	seen := map[Card]string{}
+	add := func(where string, cards []Card) {
+		for _, c := range cards {
+			if c.Suit < Spades || c.Suit > Hearts || c.Rank < Nine || c.Rank > Ace {
+				v.addf("invalid card %v in %s", c, where)
+				continue
+			}
+			if prev, ok := seen[c]; ok {
+				v.addf("card %s in both %s and %s", c.Code(), prev, where)
+				continue
+			}
+			seen[c] = where
+		}
+	}
+	for _, p := range g.Params.Players {
+		add("hand of "+string(p), g.Deal.Hands[p])
+	}
+	for p := range g.Deal.Hands {
+		if !v.isPlayer(p) {
+			v.addf("hand held by unknown player %s", p)
+		}
+	}
+	for i, m := range g.Deal.Musiks {
+		add(fmt.Sprintf("musik %d", i), m)
+	}
+	add("table cards", g.Deal.TableCards)
+	for i, t := range g.Play.CompletedTricks {
+		for _, p := range t.Plays {
+			add(fmt.Sprintf("trick %d", i+1), []Card{p.Card})
+		}
+	}
+	for _, p := range g.Play.CurrentTrick.Plays {
+		add("current trick", []Card{p.Card})
+	}
+	if g.Phase > PhaseDeal && len(seen) != 24 {
+		v.addf("expected 24 cards in play, found %d", len(seen))
+	}
+	if g.Phase <= PhaseDeal && len(seen) != 0 {
+		v.addf("cards present before the deal")
+	}
+}
+
+// checkAuction replays the bids and compares the outcome with the auction
+// state and declarer.
+func (v *validator) checkAuction() {
+	g := v.g
+	if g.Phase < PhaseAuction {
+		if g.Declarer != nil {
+			v.addf("declarer set before the auction")
+		}
+		return
+	}
+	bids := g.Auction.Bids
+	if len(bids) == 0 || bids[0].Player != g.Dealer || bids[0].Pass || bids[0].Value != g.Params.MinBid {
+		v.addf("auction must open with the dealer's bid of %d", g.Params.MinBid)
+		return
+	}
+	active := append([]PlayerID{}, g.Params.Players...)
+	turn := nextPlayer(g.Params.Players, g.Dealer)
+	high := bids[0].Value
+	var declarer *PlayerID
+	for i, b := range bids[1:] {
+		if declarer != nil {
+			v.addf("bid %d placed after the auction ended", i+2)
+			return
+		}
+		if b.Player != turn {
+			v.addf("bid %d by %s out of turn, expected %s", i+2, b.Player, turn)
+			return
+		}
+		if b.Pass {
+			active = removePlayer(active, b.Player)
+			if len(active) == 1 {
+				declarer = &active[0]
+			}
+		} else if b.Value <= high || b.Value-high < g.Params.MinRaise {
+			v.addf("bid %d of %d does not raise %d by at least %d", i+2, b.Value, high, g.Params.MinRaise)
+		} else if limit := v.bidLimit(b.Player); limit > 0 && b.Value > limit {
+			v.addf("bid %d of %d is over the limit of %d", i+2, b.Value, limit)
+		} else {
+			high = b.Value
+		}
+		turn = nextPlayer(g.Params.Players, turn)
+	}
+	switch {
+	case g.Phase == PhaseAuction && declarer != nil:
+		v.addf("auction has ended but phase is %v", g.Phase)
+	case g.Phase == PhaseAuction:
+		if g.Declarer != nil {
+			v.addf("declarer set during the auction")
+		}
+		if g.Auction.CurrentLeader != turn {
+			v.addf("auction turn is %s, bids say %s", g.Auction.CurrentLeader, turn)
+		}
+	case declarer == nil:
+		v.addf("auction has not ended but phase is %v", g.Phase)
+	case g.Declarer == nil:
+		v.addf("declarer missing, auction was won by %s", *declarer)
+	case *g.Declarer != *declarer:
+		v.addf("declarer is %s, auction was won by %s", *g.Declarer, *declarer)
+	}
+	if len(g.Auction.ActivePlayers) != len(active) {
+		v.addf("%d players active in auction, bids leave %d", len(g.Auction.ActivePlayers), len(active))
+	}
+}
+
+// bidLimit returns the limit on p's bids, or 0 for none. Only during the
+// auction do the hands show the marriages held while bidding.
+func (v *validator) bidLimit(p PlayerID) int {
+	if v.g.Phase == PhaseAuction {
+		return v.g.bidLimit(p)
+	}
+	return v.g.Params.MaxBid
+}
+
+func (v *validator) checkPhaseFields() {
+	g := v.g
+	p := g.Params
+	handSize := func(player PlayerID, want int) {
+		if got := len(g.Deal.Hands[player]); got != want {
+			v.addf("%s holds %d cards, expected %d", player, got, want)
+		}
+	}
+	switch g.Phase {
+	case PhaseAuction:
+		for _, pl := range p.Players {
+			handSize(pl, p.HandCards)
+		}
+		if len(g.Deal.Musiks) != p.MusiksCount {
+			v.addf("expected %d musiks, got %d", p.MusiksCount, len(g.Deal.Musiks))
+		}
+		if len(g.Deal.TableCards) != 0 {
+			v.addf("table cards present during the auction")
+		}
+	case PhaseTalonExchange:
+		if g.Declarer == nil {
+			return
+		}
+		for _, pl := range p.Players {
+			if pl != *g.Declarer {
+				handSize(pl, p.HandCards)
+			}
+		}
+		if g.Deal.Musiks != nil {
+			handSize(*g.Declarer, p.HandCards)
+			if len(g.Deal.TableCards) != 0 {
+				v.addf("table cards present before the musik was chosen")
+			}
+		} else {
+			handSize(*g.Declarer, p.HandCards+p.MusikSize)
+			if want := (p.MusiksCount - 1) * p.MusikSize; len(g.Deal.TableCards) != want {
+				v.addf("expected %d table cards, got %d", want, len(g.Deal.TableCards))
+			}
+		}
+	case PhasePlay, PhaseScoring, PhaseHandEnd:
+		if g.Deal.Musiks != nil {
+			v.addf("musiks still on the table in %v phase", g.Phase)
+		}
+		if want := p.MusiksCount * p.MusikSize; len(g.Deal.TableCards) != want {
+			v.addf("expected %d table cards, got %d", want, len(g.Deal.TableCards))
+		}
+		if g.Phase == PhasePlay {
+			inPlay := len(g.Play.CurrentTrick.Plays)
+			for _, pl := range p.Players {
+				inPlay += len(g.Deal.Hands[pl])
+			}
+			if g.Play.RemainingCards != inPlay {
+				v.addf("remaining cards %d, hands and current trick hold %d", g.Play.RemainingCards, inPlay)
+			}
+		} else {
+			if len(g.Play.CompletedTricks) != p.HandCards {
+				v.addf("hand ended after %d tricks, expected %d", len(g.Play.CompletedTricks), p.HandCards)
+			}
+			if g.Play.RemainingCards != 0 {
+				v.addf("remaining cards %d after the last trick", g.Play.RemainingCards)
+			}
+		}
+	}
+	if g.Phase < PhasePlay && (len(g.Play.CompletedTricks) > 0 || len(g.Play.CurrentTrick.Plays) > 0) {
+		v.addf("tricks played in %v phase", g.Phase)
+	}
+}
+
+// checkTricks replays the tricks to verify turn order, winners, trump and
+// deal points.
+func (v *validator) checkTricks() {
+	g := v.g
+	if g.Phase < PhasePlay || g.Declarer == nil {
+		return
+	}
+	points := map[PlayerID]int{}
+	leader := *g.Declarer
+	var trump *Suit
+	var lastWinner *PlayerID
+	check := func(name string, t Trick, complete bool) {
+		if t.Leader != leader {
+			v.addf("%s led by %s, expected %s", name, t.Leader, leader)
+		}
+		turn := leader
+		for i, pl := range t.Plays {
+			if pl.Player != turn {
+				v.addf("%s play %d by %s out of turn, expected %s", name, i+1, pl.Player, turn)
+			}
+			turn = nextPlayer(g.Params.Players, turn)
+			if pl.AnnouncedMarriage == nil {
+				continue
+			}
+			if i != 0 || *pl.AnnouncedMarriage != pl.Card.Suit || (pl.Card.Rank != King && pl.Card.Rank != Queen) {
+				v.addf("%s has an invalid marriage announcement", name)
+				continue
+			}
+			if lastWinner == nil && g.Params.NoMarriageOnFirstTrick {
+				v.addf("%s announces a marriage on the first trick", name)
+			}
+			s := *pl.AnnouncedMarriage
+			trump = &s
+			points[pl.Player] += MarriageValue(s)
+		}
+		if len(t.Plays) > 0 && (t.LedSuit == nil || *t.LedSuit != t.Plays[0].Card.Suit) {
+			v.addf("%s led suit does not match the first card", name)
+		}
+		if len(t.Plays) == 0 && t.LedSuit != nil {
+			v.addf("%s has a led suit but no plays", name)
+		}
+		if !complete {
+			return
+		}
+		if len(t.Plays) != len(g.Params.Players) {
+			v.addf("%s has %d plays", name, len(t.Plays))
+			return
+		}
+		best := 0
+		for i := 1; i < len(t.Plays); i++ {
+			if firstCardBetter(t.Plays[i].Card, t.Plays[best].Card, t.Plays[0].Card.Suit, trump) {
+				best = i
+			}
+		}
+		if t.WinningPlayIndex != best {
+			v.addf("%s won by play %d, expected %d", name, t.WinningPlayIndex+1, best+1)
+		}
+		winner := t.Plays[best].Player
+		for _, pl := range t.Plays {
+			points[winner] += PointsFor(pl.Card.Rank)
+		}
+		leader = winner
+		lastWinner = &winner
+	}
+	for i, t := range g.Play.CompletedTricks {
+		check(fmt.Sprintf("trick %d", i+1), t, true)
+	}
+	check("current trick", g.Play.CurrentTrick, false)
+	if (trump == nil) != (g.Play.Trump == nil) || (trump != nil && *trump != *g.Play.Trump) {
+		v.addf("trump does not match the last announced marriage")
+	}
+	if (lastWinner == nil) != (g.Play.LastTrickWinner == nil) || (lastWinner != nil && *lastWinner != *g.Play.LastTrickWinner) {
+		v.addf("last trick winner does not match the tricks")
+	}
+	if g.Phase >= PhaseScoring && lastWinner != nil {
+		for _, c := range g.Deal.TableCards {
+			points[*lastWinner] += PointsFor(c.Rank)
+		}
+	}
+	for _, p := range g.Params.Players {
+		if g.Scores.DealPoints[p] != points[p] {
+			v.addf("%s has %d deal points, tricks and marriages give %d", p, g.Scores.DealPoints[p], points[p])
+		}
+	}
+}
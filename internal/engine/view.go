@@ -0,0 +1,104 @@
+package engine
+
+// Marriage records a marriage announcement.
+type Marriage struct {
+	Player PlayerID
+	Suit   Suit
+}
+
+// PlayerView is the part of the game state one player is allowed to see.
+// All slices and maps are copies.
+type PlayerView struct {
+	Seat     PlayerID
+	Params   GameParams
+	Dealer   PlayerID
+	Phase    Phase
+	Declarer *PlayerID
+
+	Hand      []Card
+	HandSizes map[PlayerID]int
+	// MusikCount is the number of musiks still face down on the table.
+	MusikCount int
+	// OwnDiscards holds the cards this player discarded as declarer; it is
+	// empty when the table cards are too few to hold them.
+	OwnDiscards []Card
+
+	Bids       []AuctionBid
+	HighestBid int
+
+	Trump           *Suit
+	Marriages       []Marriage
+	CurrentTrick    Trick
+	CompletedTricks []Trick
+
+	DealPoints map[PlayerID]int
+	Cumulative map[PlayerID]int
+
+	LegalBids  []int
+	LegalPlays []Card
+}
+
+// View returns what player p can see of the game.
+func (g *GameState) View(p PlayerID) PlayerView {
+	c := g.Clone()
+	v := PlayerView{
+		Seat:            p,
+		Params:          c.Params,
+		Dealer:          c.Dealer,
+		Phase:           c.Phase,
+		Declarer:        c.Declarer,
+		Hand:            c.Deal.Hands[p],
+		HandSizes:       map[PlayerID]int{},
+		MusikCount:      len(c.Deal.Musiks),
+		Bids:            c.Auction.Bids,
+		HighestBid:      c.HighestBid(),
+		Trump:           c.Play.Trump,
+		CurrentTrick:    c.Play.CurrentTrick,
+		CompletedTricks: c.Play.CompletedTricks,
+		DealPoints:      c.Scores.DealPoints,
+		Cumulative:      c.Scores.Cumulative,
+		LegalBids:       g.LegalBids(p),
+	}
+	for q, h := range c.Deal.Hands {
+		v.HandSizes[q] = len(h)
+	}
+	if c.Declarer != nil && *c.Declarer == p && c.Phase >= PhasePlay {
+		// a state that fails Validate may hold fewer table cards
+		if unchosen := (c.Params.MusiksCount - 1) * c.Params.MusikSize; unchosen >= 0 && unchosen <= len(c.Deal.TableCards) {
+			v.OwnDiscards = c.Deal.TableCards[unchosen:]
+		}
+	}
+	for _, t := range append(append([]Trick{}, c.Play.CompletedTricks...), c.Play.CurrentTrick) {
+		for _, pl := range t.Plays {
+			if pl.AnnouncedMarriage != nil {
+				v.Marriages = append(v.Marriages, Marriage{Player: pl.Player, Suit: *pl.AnnouncedMarriage})
+			}
+		}
+	}
+	if g.Phase == PhasePlay && g.CurrentTurnPlayer() == p {
+		v.LegalPlays = g.LegalPlays(p)
+	}
+	return v
+}
+
+// Others returns the players other than the view's seat, in seat order.
+func (v PlayerView) Others() []PlayerID {
+	var out []PlayerID
+	for _, p := range v.Params.Players {
+		if p != v.Seat {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// MarriageAllowed reports whether the rules let a marriage be announced on
+// the next lead.
+func (v PlayerView) MarriageAllowed() bool {
+	return !v.Params.NoMarriageOnFirstTrick || len(v.CompletedTricks) > 0
+}
+
+// Leading reports whether the viewing player is about to lead a trick.
+func (v PlayerView) Leading() bool {
+	return v.Phase == PhasePlay && len(v.CurrentTrick.Plays) == 0 && v.CurrentTrick.Leader == v.Seat
+}
@@ -0,0 +1,143 @@
+// Package estimate predicts how a hand is likely to score as declarer.
+package estimate
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// Options configures ContractOdds.
+type Options struct {
+	// Samples is the number of deals of the unseen cards to play out (default 200).
+	Samples int
+	// Rand drives the sampling and seeds the bots; a source seeded with 1 is
+	// used when nil.
+	Rand *rand.Rand
+	// Declarer and Defender build the bots playing each sample (default HeuristicBot).
+	Declarer player.PlayerFactory
+	Defender player.PlayerFactory
+}
+
+// Distribution counts declarer deal points (tricks + marriages) over samples.
+type Distribution struct {
+	Samples int
+	Points  map[int]int
+}
+
+// AtLeast returns the share of samples in which the declarer scored at least points.
+func (d Distribution) AtLeast(points int) float64 {
+	if d.Samples == 0 {
+		return 0
+	}
+	n := 0
+	for p, c := range d.Points {
+		if p >= points {
+			n += c
+		}
+	}
+	return float64(n) / float64(d.Samples)
+}
+
+// Mean returns the average declarer deal points.
+func (d Distribution) Mean() float64 {
+	if d.Samples == 0 {
+		return 0
+	}
+	sum := 0
+	for p, c := range d.Points {
+		sum += p * c
+	}
+	return float64(sum) / float64(d.Samples)
+}
+
+// Values returns the observed point totals in ascending order.
+func (d Distribution) Values() []int {
+	out := make([]int, 0, len(d.Points))
+	for p := range d.Points {
+		out = append(out, p)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// ContractOdds samples the cards unseen from hand (musiks and opponent hand),
+// plays each sample out with hand's owner as declarer and returns the
+// distribution of declarer points. The owner is params.Players[0]; the
+// declarer takes the contract at params.MinBid. It fails unless hand is a
+// full hand of distinct cards.
+func ContractOdds(hand []engine.Card, params engine.GameParams, opts Options) (Distribution, error) {
+	players := params.Players
+	if len(players) == 0 {
+		players = []engine.PlayerID{"declarer", "defender"}
+	}
+	if len(players) != 2 {
+		return Distribution{}, fmt.Errorf("only 2 players supported")
+	}
+	if opts.Samples <= 0 {
+		opts.Samples = 200
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(1))
+	}
+	heuristic := func(int64) player.Player { return player.NewHeuristicBot() }
+	if opts.Declarer == nil {
+		opts.Declarer = heuristic
+	}
+	if opts.Defender == nil {
+		opts.Defender = heuristic
+	}
+	declarer, defender := players[0], players[1]
+	if n := engine.NewGame(params, declarer, players, nil).Params.HandCards; len(hand) != n {
+		return Distribution{}, fmt.Errorf("hand of %d cards, want %d", len(hand), n)
+	}
+	unseen, err := unseenCards(hand)
+	if err != nil {
+		return Distribution{}, err
+	}
+
+	dist := Distribution{Points: map[int]int{}}
+	for range opts.Samples {
+		// dealer auto-bids the minimum; the defender passes straight away
+		g := engine.NewGame(params, declarer, players, nil)
+		match.Shuffle(unseen, opts.Rand)
+		deck := append(append([]engine.Card{}, hand...), unseen...)
+		if err := match.DealDeck(g, deck); err != nil {
+			return Distribution{}, err
+		}
+		if err := g.PlaceBid(defender, 0); err != nil {
+			return Distribution{}, err
+		}
+		bots := map[engine.PlayerID]player.Player{
+			declarer: opts.Declarer(opts.Rand.Int63()),
+			defender: opts.Defender(opts.Rand.Int63()),
+		}
+		if err := match.PlayHand(g, bots); err != nil {
+			return Distribution{}, err
+		}
+		dist.Points[g.Scores.DealPoints[declarer]]++
+		dist.Samples++
+	}
+	return dist, nil
+}
+
+func unseenCards(hand []engine.Card) ([]engine.Card, error) {
+	held := map[engine.Card]bool{}
+	for _, c := range hand {
+		if held[c] {
+			return nil, fmt.Errorf("duplicate card in hand: %v", c)
+		}
+		held[c] = true
+	}
+	var out []engine.Card
+	for _, c := range match.NewDeck() {
+		if !held[c] {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
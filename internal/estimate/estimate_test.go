@@ -0,0 +1,69 @@
+package estimate
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+func hand(t *testing.T, s string) []engine.Card {
+	t.Helper()
+	cards, err := engine.ParseCards(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cards
+}
+
+func TestContractOddsDistribution(t *testing.T) {
+	odds := func(s string) Distribution {
+		t.Helper()
+		d, err := ContractOdds(hand(t, s), engine.GameParams{}, Options{Samples: 60, Rand: rand.New(rand.NewSource(4))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Samples != 60 {
+			t.Fatalf("%d samples, want 60", d.Samples)
+		}
+		n := 0
+		for _, c := range d.Points {
+			n += c
+		}
+		if n != d.Samples {
+			t.Fatalf("points counted %d times in %d samples", n, d.Samples)
+		}
+		if v := d.Values(); !slices.IsSorted(v) || d.Mean() < float64(v[0]) || d.Mean() > float64(v[len(v)-1]) {
+			t.Fatalf("values %v, mean %v", v, d.Mean())
+		}
+		if d.AtLeast(0) != 1 || d.AtLeast(121) > d.AtLeast(100) {
+			t.Fatalf("at least 0: %v, 100: %v, 121: %v", d.AtLeast(0), d.AtLeast(100), d.AtLeast(121))
+		}
+		return d
+	}
+	// aces, tens and two marriages against nines and jacks
+	strong := odds("AH 10H KH QH AS 10S KS QS AD 10D")
+	weak := odds("9H JH 9S JS 9D JD 9C JC QD KC")
+	if strong.AtLeast(120) <= weak.AtLeast(120) || strong.Mean() <= weak.Mean() {
+		t.Errorf("strong hand makes 120 in %.2f (mean %.0f), weak hand in %.2f (mean %.0f)",
+			strong.AtLeast(120), strong.Mean(), weak.AtLeast(120), weak.Mean())
+	}
+	if strong.AtLeast(100) < 0.9 {
+		t.Errorf("strong hand makes 100 in only %.2f", strong.AtLeast(100))
+	}
+}
+
+func TestContractOddsRejectsBadHands(t *testing.T) {
+	for name, s := range map[string]string{
+		"duplicate": "AH AH KH QH AS 10S KS QS AD 10D",
+		"short":     "AH 10H KH QH AS",
+	} {
+		if _, err := ContractOdds(hand(t, s), engine.GameParams{}, Options{Samples: 1}); err == nil {
+			t.Errorf("%s hand accepted", name)
+		}
+	}
+	if _, err := ContractOdds(hand(t, "AH 10H KH QH AS 10S KS QS AD 10D"), engine.GameParams{Players: []engine.PlayerID{"A"}}, Options{Samples: 1}); err == nil {
+		t.Error("one player accepted")
+	}
+}
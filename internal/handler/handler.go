@@ -1,4 +1,15 @@
 package handler
 
+import (
+	"sync"
+
+	"github.com/ZygmuntJakub/1000/internal/rules"
+)
+
 type Handler struct {
+	// Profile is the rules profile games on the server are played by.
+	Profile rules.Profile
+
+	mu      sync.Mutex
+	players []Player
 }
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/hint"
+	"github.com/ZygmuntJakub/1000/internal/player"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// maxHintSamples bounds the play-outs one request may ask for.
+	maxHintSamples = 500
+	// maxHintBudget bounds the advising bot's thinking time.
+	maxHintBudget = 2 * time.Second
+)
+
+// HintRequest is the body of POST /hint: the view of the player to act, as
+// the engine redacts it, and optionally the advising bot spec.
+type HintRequest struct {
+	View    *engine.PlayerView `json:"view"`
+	Bot     string             `json:"bot"`
+	Samples int                `json:"samples"`
+	Seed    int64              `json:"seed"`
+}
+
+// Hint recommends an action for the view in the request.
+func (h *Handler) Hint(c echo.Context) error {
+	var req HintRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.View == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "view is required")
+	}
+	params := req.View.Params
+	params.Players = nil
+	if !reflect.DeepEqual(params, h.Profile.Effective()) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("view params do not match the %q rules", h.Profile.Name))
+	}
+	if req.Samples > maxHintSamples {
+		return echo.NewHTTPError(http.StatusBadRequest, "at most 500 samples")
+	}
+	if req.Bot != "" {
+		_, cfg, err := player.ParseSpec(req.Bot)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if cfg.TimeBudget > maxHintBudget || cfg.Personality.Delay > 0 || cfg.Model != "" || cfg.Command != "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "bot may think for at most 2s, without delay, model file or command")
+		}
+	}
+	res, err := hint.Advise(c.Request().Context(), *req.View, hint.Options{Bot: req.Bot, Samples: req.Samples, Seed: req.Seed})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -1,11 +1,76 @@
 package handler
 
-import "github.com/labstack/echo/v4"
+import (
+	"net/http"
+
+	"github.com/ZygmuntJakub/1000/internal/player"
+	"github.com/labstack/echo/v4"
+)
+
+// Player is a seat filler at the table: a human or a bot built from a spec.
+type Player struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Bot is the bot spec, e.g. "heuristic:hard"; empty for humans.
+	Bot        string `json:"bot,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	TimeBudget string `json:"timeBudget,omitempty"`
+}
+
+// AddPlayerRequest is the body of POST /player.
+type AddPlayerRequest struct {
+	Name string `json:"name"`
+	Bot  string `json:"bot"`
+}
+
+// BotType describes a registered bot type for GET /bots.
+type BotType struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
 
 func (h *Handler) AddPlayer(c echo.Context) error {
-	return c.String(200, "to do")
+	var req AddPlayerRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	p := Player{Name: req.Name, Bot: req.Bot}
+	if req.Bot != "" {
+		t, cfg, err := player.ParseSpec(req.Bot)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if p.Name == "" {
+			p.Name = t.Name
+		}
+		p.Difficulty = cfg.Difficulty.String()
+		if cfg.TimeBudget > 0 {
+			p.TimeBudget = cfg.TimeBudget.String()
+		}
+	}
+	if p.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name or bot is required")
+	}
+
+	h.mu.Lock()
+	p.ID = len(h.players) + 1
+	h.players = append(h.players, p)
+	h.mu.Unlock()
+	return c.JSON(http.StatusCreated, p)
 }
 
 func (h *Handler) ListPlayers(c echo.Context) error {
-	return c.String(200, "to do")
+	h.mu.Lock()
+	players := append([]Player{}, h.players...)
+	h.mu.Unlock()
+	return c.JSON(http.StatusOK, players)
+}
+
+// ListBots lists the bot types a POST /player spec may name.
+func (h *Handler) ListBots(c echo.Context) error {
+	var out []BotType
+	for _, t := range player.BotTypes() {
+		out = append(out, BotType{Name: t.Name, Description: t.Description})
+	}
+	return c.JSON(http.StatusOK, out)
 }
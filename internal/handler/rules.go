@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/rules"
+	"github.com/labstack/echo/v4"
+)
+
+// RulesResponse is the body of GET /rules.
+type RulesResponse struct {
+	Profile rules.Profile `json:"profile"`
+	// Params are the engine parameters the profile plays with.
+	Params engine.GameParams `json:"params"`
+	// Builtin lists the profiles the server can be started with by name.
+	Builtin []rules.Profile `json:"builtin"`
+}
+
+// Rules describes the rules profile the server plays by.
+func (h *Handler) Rules(c echo.Context) error {
+	return c.JSON(http.StatusOK, RulesResponse{Profile: h.Profile, Params: h.Profile.Effective(), Builtin: rules.Builtin()})
+}
@@ -0,0 +1,581 @@
+// Package handrecord reads and writes hand histories in a PBN-like text format.
+//
+// A record is a block of tag lines followed by the play section:
+//
+//	[Event "Friday club"]
+//	[Players "P1 P2"]
+//	[Dealer "P1"]
+//	[Rules "MinBid=100 MinRaise=10 HandCards=10 MusiksCount=2 MusikSize=2 MaxGamePoints=1000"]
+//	[Seed "42"]
+//	[Scores "P1:0 P2:0"]
+//	[Hand "P1:9S JS QS KS 10S AS 9C JC QC KC"]
+//	[Hand "P2:10C AC 9D JD QD KD 10D AD 9H JH"]
+//	[Musiks "QH KH / 10H AH"]
+//	[Auction "P1 100, P2 110, P1 pass"]
+//	[Musik "0"]
+//	[Discard "9D JD"]
+//	[Play]
+//	P2 KH+ P1 KS
+//	...
+//	[DealPoints "P1:0 P2:220"]
+//	[Cumulative "P1:0 P2:110"]
+//
+// The Rules tag also lists the variant options of engine.GameParams that
+// are on, such as "BarrelAt=880 NoMarriageOnFirstTrick=true". Each play
+// line is one trick in play order; a "+" after a card marks a marriage
+// announcement. The Hand and Musiks tags may be left out of a record with
+// a Seed, whose cards are then dealt from the seed. Records are separated
+// by blank lines and lines starting with ";" are comments.
+package handrecord
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+)
+
+// Record is the history of a single hand.
+type Record struct {
+	Event   string
+	Players []engine.PlayerID
+	Dealer  engine.PlayerID
+	Params  engine.GameParams
+	// Seed, if set, is the seed of the rand.Source the hand was dealt from
+	// with match.Deal; records without Hand tags are dealt from it.
+	Seed *int64
+	// Scores holds cumulative scores before the hand.
+	Scores map[engine.PlayerID]int
+	Hands  map[engine.PlayerID][]engine.Card
+	Musiks [][]engine.Card
+	// Auction includes the dealer's automatic opening bid.
+	Auction  []engine.AuctionBid
+	Musik    int // -1 until the declarer chooses
+	Discards []engine.Card
+	Tricks   []engine.Trick
+	// DealPoints and Cumulative are set once the hand has ended.
+	DealPoints map[engine.PlayerID]int
+	Cumulative map[engine.PlayerID]int
+}
+
+// New starts a record from a game that has just been dealt.
+func New(g *engine.GameState) *Record {
+	r := &Record{
+		Players: append([]engine.PlayerID{}, g.Params.Players...),
+		Dealer:  g.Dealer,
+		Params:  g.Params,
+		Scores:  map[engine.PlayerID]int{},
+		Hands:   map[engine.PlayerID][]engine.Card{},
+		Musik:   -1,
+	}
+	r.Params.Players = nil
+	for p, v := range g.Scores.Cumulative {
+		r.Scores[p] = v
+	}
+	for p, h := range g.Deal.Hands {
+		r.Hands[p] = append([]engine.Card{}, h...)
+	}
+	for _, m := range g.Deal.Musiks {
+		r.Musiks = append(r.Musiks, append([]engine.Card{}, m...))
+	}
+	r.Update(g)
+	return r
+}
+
+// Update copies the auction, musik exchange, play and result from g, which
+// must be the game the record was started from.
+func (r *Record) Update(g *engine.GameState) {
+	r.Auction = append([]engine.AuctionBid{}, g.Auction.Bids...)
+	if g.Deal.Musiks == nil && len(r.Musiks) > 0 {
+		unchosen := g.Deal.TableCards[:(len(r.Musiks)-1)*g.Params.MusikSize]
+		for i, m := range r.Musiks {
+			if !contains(unchosen, m[0]) {
+				r.Musik = i
+				break
+			}
+		}
+		if g.Phase >= engine.PhasePlay {
+			r.Discards = append([]engine.Card{}, g.Deal.TableCards[len(unchosen):]...)
+		}
+	}
+	r.Tricks = nil
+	for _, t := range g.Play.CompletedTricks {
+		r.Tricks = append(r.Tricks, copyTrick(t))
+	}
+	if len(g.Play.CurrentTrick.Plays) > 0 {
+		r.Tricks = append(r.Tricks, copyTrick(g.Play.CurrentTrick))
+	}
+	if g.Phase == engine.PhaseHandEnd {
+		r.DealPoints = map[engine.PlayerID]int{}
+		r.Cumulative = map[engine.PlayerID]int{}
+		for _, p := range r.Players {
+			r.DealPoints[p] = g.Scores.DealPoints[p]
+			r.Cumulative[p] = g.Scores.Cumulative[p]
+		}
+	}
+}
+
+func copyTrick(t engine.Trick) engine.Trick {
+	return engine.Trick{Leader: t.Leader, Plays: append([]engine.Play{}, t.Plays...)}
+}
+
+func contains(cards []engine.Card, c engine.Card) bool {
+	for _, x := range cards {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Start deals the record's cards into a new game, ready for the auction.
+// Without Hand and Musiks tags the cards are dealt from Seed.
+func (r *Record) Start() (*engine.GameState, error) {
+	params := r.Params
+	params.Players = r.Players
+	g := engine.NewGame(params, r.Dealer, r.Players, r.Scores)
+	if len(r.Hands) == 0 && len(r.Musiks) == 0 && r.Seed != nil {
+		if err := match.Deal(g, rand.New(rand.NewSource(*r.Seed))); err != nil {
+			return g, fmt.Errorf("deal from seed %d: %w", *r.Seed, err)
+		}
+		return g, nil
+	}
+	hands := map[engine.PlayerID][]engine.Card{}
+	for p, h := range r.Hands {
+		hands[p] = append([]engine.Card{}, h...)
+	}
+	var musiks [][]engine.Card
+	for _, m := range r.Musiks {
+		musiks = append(musiks, append([]engine.Card{}, m...))
+	}
+	if err := g.SetDealtCards(hands, musiks); err != nil {
+		return g, fmt.Errorf("deal: %w", err)
+	}
+	return g, nil
+}
+
+// Actions lists the players' decisions in the order they were taken,
+// starting after the dealer's automatic opening bid. Musik and discard
+// actions are attributed to the highest bidder.
+func (r *Record) Actions() []engine.Action {
+	var out []engine.Action
+	var declarer engine.PlayerID
+	high := -1
+	for i, b := range r.Auction {
+		if !b.Pass && b.Value > high {
+			declarer, high = b.Player, b.Value
+		}
+		if i == 0 && b.Player == r.Dealer && !b.Pass {
+			continue
+		}
+		out = append(out, engine.Action{Type: engine.ActionBid, Player: b.Player, Value: b.Value})
+	}
+	if r.Musik >= 0 {
+		out = append(out, engine.Action{Type: engine.ActionChooseMusik, Player: declarer, Index: r.Musik})
+	}
+	if r.Discards != nil {
+		out = append(out, engine.Action{Type: engine.ActionDiscard, Player: declarer, Cards: append([]engine.Card{}, r.Discards...)})
+	}
+	for _, t := range r.Tricks {
+		for _, p := range t.Plays {
+			out = append(out, engine.Action{Type: engine.ActionPlayCard, Player: p.Player, Card: p.Card, Marriage: p.AnnouncedMarriage != nil})
+		}
+	}
+	return out
+}
+
+// Replay runs the record through the engine and returns the resulting game.
+// If the record holds a result, it must match the engine's.
+func (r *Record) Replay() (*engine.GameState, error) {
+	g, err := r.Start()
+	if err != nil {
+		return g, err
+	}
+	for i, b := range r.Auction {
+		if i == 0 && b == g.Auction.Bids[0] {
+			continue
+		}
+		if err := g.PlaceBid(b.Player, b.Value); err != nil {
+			return g, fmt.Errorf("bid %d: %w", i+1, err)
+		}
+	}
+	if r.Musik >= 0 {
+		if g.Declarer == nil {
+			return g, fmt.Errorf("musik chosen before the auction ended")
+		}
+		if err := g.ChooseMusik(*g.Declarer, r.Musik); err != nil {
+			return g, fmt.Errorf("musik: %w", err)
+		}
+	}
+	if r.Discards != nil {
+		if g.Declarer == nil {
+			return g, fmt.Errorf("discard before the auction ended")
+		}
+		if err := g.Discard(*g.Declarer, append([]engine.Card{}, r.Discards...)); err != nil {
+			return g, fmt.Errorf("discard: %w", err)
+		}
+	}
+	for i, t := range r.Tricks {
+		for _, p := range t.Plays {
+			if err := g.PlayCard(p.Player, p.Card, p.AnnouncedMarriage != nil); err != nil {
+				return g, fmt.Errorf("trick %d: %s %s: %w", i+1, p.Player, p.Card.Code(), err)
+			}
+		}
+	}
+	if r.DealPoints != nil {
+		if g.Phase != engine.PhaseHandEnd {
+			return g, fmt.Errorf("record has a result but the hand ended in %v phase", g.Phase)
+		}
+		for _, p := range r.Players {
+			if g.Scores.DealPoints[p] != r.DealPoints[p] {
+				return g, fmt.Errorf("deal points of %s: record %d, engine %d", p, r.DealPoints[p], g.Scores.DealPoints[p])
+			}
+			if r.Cumulative != nil && g.Scores.Cumulative[p] != r.Cumulative[p] {
+				return g, fmt.Errorf("cumulative score of %s: record %d, engine %d", p, r.Cumulative[p], g.Scores.Cumulative[p])
+			}
+		}
+	}
+	return g, nil
+}
+
+// Write writes records separated by blank lines.
+func Write(w io.Writer, records ...*Record) error {
+	bw := bufio.NewWriter(w)
+	for i, r := range records {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		if err := writeRecord(bw, r); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func writeRecord(w *bufio.Writer, r *Record) error {
+	for _, p := range r.Players {
+		if p == "" || strings.ContainsAny(string(p), " \t\n:,+/\"") {
+			return fmt.Errorf("player id %q cannot be written", p)
+		}
+	}
+	tag := func(name, value string) {
+		fmt.Fprintf(w, "[%s %s]\n", name, strconv.Quote(value))
+	}
+	if r.Event != "" {
+		tag("Event", r.Event)
+	}
+	names := make([]string, len(r.Players))
+	for i, p := range r.Players {
+		names[i] = string(p)
+	}
+	tag("Players", strings.Join(names, " "))
+	tag("Dealer", string(r.Dealer))
+	tag("Rules", formatRules(r.Params))
+	if r.Seed != nil {
+		tag("Seed", strconv.FormatInt(*r.Seed, 10))
+	}
+	tag("Scores", r.formatScores(r.Scores))
+	// a record dealt from its seed may leave the cards out
+	if len(r.Hands) > 0 {
+		for _, p := range r.Players {
+			tag("Hand", string(p)+":"+engine.FormatCards(r.Hands[p]))
+		}
+	}
+	if len(r.Musiks) > 0 {
+		musiks := make([]string, len(r.Musiks))
+		for i, m := range r.Musiks {
+			musiks[i] = engine.FormatCards(m)
+		}
+		tag("Musiks", strings.Join(musiks, " / "))
+	}
+	bids := make([]string, len(r.Auction))
+	for i, b := range r.Auction {
+		if b.Pass {
+			bids[i] = string(b.Player) + " pass"
+		} else {
+			bids[i] = fmt.Sprintf("%s %d", b.Player, b.Value)
+		}
+	}
+	tag("Auction", strings.Join(bids, ", "))
+	if r.Musik >= 0 {
+		tag("Musik", strconv.Itoa(r.Musik))
+	}
+	if r.Discards != nil {
+		tag("Discard", engine.FormatCards(r.Discards))
+	}
+	if len(r.Tricks) > 0 {
+		w.WriteString("[Play]\n")
+		for _, t := range r.Tricks {
+			plays := make([]string, len(t.Plays))
+			for i, p := range t.Plays {
+				plays[i] = string(p.Player) + " " + p.Card.Code()
+				if p.AnnouncedMarriage != nil {
+					plays[i] += "+"
+				}
+			}
+			w.WriteString(strings.Join(plays, " ") + "\n")
+		}
+	}
+	if r.DealPoints != nil {
+		tag("DealPoints", r.formatScores(r.DealPoints))
+	}
+	if r.Cumulative != nil {
+		tag("Cumulative", r.formatScores(r.Cumulative))
+	}
+	return nil
+}
+
+func (r *Record) formatScores(scores map[engine.PlayerID]int) string {
+	out := make([]string, len(r.Players))
+	for i, p := range r.Players {
+		out[i] = fmt.Sprintf("%s:%d", p, scores[p])
+	}
+	return strings.Join(out, " ")
+}
+
+// Read parses all records from rd.
+func Read(rd io.Reader) ([]*Record, error) {
+	var (
+		records []*Record
+		cur     *Record
+		inPlay  bool
+	)
+	sc := bufio.NewScanner(rd)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(text, ";") {
+			continue
+		}
+		if text == "" {
+			cur, inPlay = nil, false
+			continue
+		}
+		if cur == nil {
+			cur = &Record{Musik: -1}
+			records = append(records, cur)
+		}
+		var err error
+		if strings.HasPrefix(text, "[") {
+			inPlay, err = cur.parseTag(text)
+		} else if inPlay {
+			err = cur.parseTrick(text)
+		} else {
+			err = fmt.Errorf("unexpected text outside play section")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for i, r := range records {
+		if len(r.Players) == 0 {
+			return nil, fmt.Errorf("record %d: missing Players tag", i+1)
+		}
+	}
+	return records, nil
+}
+
+// parseTag handles a tag line and reports whether it opens the play section.
+func (r *Record) parseTag(text string) (bool, error) {
+	if !strings.HasSuffix(text, "]") {
+		return false, fmt.Errorf("unterminated tag")
+	}
+	name, raw, _ := strings.Cut(text[1:len(text)-1], " ")
+	if name == "Play" {
+		return true, nil
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(raw))
+	if err != nil {
+		return false, fmt.Errorf("tag %s: value must be quoted", name)
+	}
+	switch name {
+	case "Event":
+		r.Event = value
+	case "Players":
+		for _, f := range strings.Fields(value) {
+			r.Players = append(r.Players, engine.PlayerID(f))
+		}
+	case "Dealer":
+		r.Dealer = engine.PlayerID(value)
+	case "Rules":
+		err = parseRules(value, &r.Params)
+	case "Seed":
+		var seed int64
+		seed, err = strconv.ParseInt(value, 10, 64)
+		r.Seed = &seed
+	case "Scores":
+		r.Scores, err = parseScores(value)
+	case "Hand":
+		p, cards, ok := strings.Cut(value, ":")
+		if !ok {
+			return false, fmt.Errorf("tag Hand: expected player:cards")
+		}
+		if r.Hands == nil {
+			r.Hands = map[engine.PlayerID][]engine.Card{}
+		}
+		r.Hands[engine.PlayerID(p)], err = engine.ParseCards(cards)
+	case "Musiks":
+		for _, m := range strings.Split(value, "/") {
+			var cards []engine.Card
+			if cards, err = engine.ParseCards(m); err != nil {
+				break
+			}
+			r.Musiks = append(r.Musiks, cards)
+		}
+	case "Auction":
+		err = r.parseAuction(value)
+	case "Musik":
+		r.Musik, err = strconv.Atoi(value)
+	case "Discard":
+		r.Discards, err = engine.ParseCards(value)
+	case "DealPoints":
+		r.DealPoints, err = parseScores(value)
+	case "Cumulative":
+		r.Cumulative, err = parseScores(value)
+	default:
+		// unknown tags are ignored, as in PBN
+	}
+	if err != nil {
+		return false, fmt.Errorf("tag %s: %w", name, err)
+	}
+	return false, nil
+}
+
+// formatRules lists the parameters for the Rules tag, leaving out the
+// variant options that are off.
+func formatRules(p engine.GameParams) string {
+	s := fmt.Sprintf("MinBid=%d MinRaise=%d HandCards=%d MusiksCount=%d MusikSize=%d MaxGamePoints=%d",
+		p.MinBid, p.MinRaise, p.HandCards, p.MusiksCount, p.MusikSize, p.MaxGamePoints)
+	ints, flags := ruleFields(&p)
+	for _, k := range variantRules {
+		if dst, ok := ints[k]; ok && *dst != 0 {
+			s += fmt.Sprintf(" %s=%d", k, *dst)
+		}
+		if dst, ok := flags[k]; ok && *dst {
+			s += " " + k + "=true"
+		}
+	}
+	return s
+}
+
+// variantRules orders the variant options in the Rules tag.
+var variantRules = []string{"MaxBid", "MaxBidWithoutMarriage", "NoMarriageOnFirstTrick", "ScoreRounding",
+	"RedealFourNines", "RedealBelow", "BarrelAt"}
+
+func ruleFields(params *engine.GameParams) (map[string]*int, map[string]*bool) {
+	ints := map[string]*int{
+		"MinBid":                &params.MinBid,
+		"MinRaise":              &params.MinRaise,
+		"HandCards":             &params.HandCards,
+		"MusiksCount":           &params.MusiksCount,
+		"MusikSize":             &params.MusikSize,
+		"MaxGamePoints":         &params.MaxGamePoints,
+		"MaxBid":                &params.MaxBid,
+		"MaxBidWithoutMarriage": &params.MaxBidWithoutMarriage,
+		"ScoreRounding":         &params.ScoreRounding,
+		"RedealBelow":           &params.RedealBelow,
+		"BarrelAt":              &params.BarrelAt,
+	}
+	flags := map[string]*bool{
+		"NoMarriageOnFirstTrick": &params.NoMarriageOnFirstTrick,
+		"RedealFourNines":        &params.RedealFourNines,
+	}
+	return ints, flags
+}
+
+func parseRules(value string, params *engine.GameParams) error {
+	ints, flags := ruleFields(params)
+	for _, f := range strings.Fields(value) {
+		k, v, _ := strings.Cut(f, "=")
+		if dst, ok := flags[k]; ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("rule %s: %w", k, err)
+			}
+			*dst = b
+			continue
+		}
+		dst, ok := ints[k]
+		if !ok {
+			return fmt.Errorf("unknown rule %q", k)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", k, err)
+		}
+		*dst = n
+	}
+	return nil
+}
+
+func parseScores(value string) (map[engine.PlayerID]int, error) {
+	out := map[engine.PlayerID]int{}
+	for _, f := range strings.Fields(value) {
+		p, v, ok := strings.Cut(f, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected player:points, got %q", f)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		out[engine.PlayerID(p)] = n
+	}
+	return out, nil
+}
+
+func (r *Record) parseAuction(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		f := strings.Fields(entry)
+		if len(f) == 0 {
+			continue
+		}
+		if len(f) != 2 {
+			return fmt.Errorf("expected player and bid, got %q", entry)
+		}
+		b := engine.AuctionBid{Player: engine.PlayerID(f[0])}
+		if f[1] == "pass" {
+			b.Pass = true
+		} else {
+			v, err := strconv.Atoi(f[1])
+			if err != nil {
+				return fmt.Errorf("invalid bid %q", f[1])
+			}
+			b.Value = v
+		}
+		r.Auction = append(r.Auction, b)
+	}
+	return nil
+}
+
+func (r *Record) parseTrick(text string) error {
+	f := strings.Fields(text)
+	if len(f) == 0 || len(f)%2 != 0 {
+		return fmt.Errorf("expected player and card pairs")
+	}
+	t := engine.Trick{Leader: engine.PlayerID(f[0])}
+	for i := 0; i < len(f); i += 2 {
+		code, marriage := strings.CutSuffix(f[i+1], "+")
+		c, err := engine.ParseCard(code)
+		if err != nil {
+			return err
+		}
+		p := engine.Play{Player: engine.PlayerID(f[i]), Card: c}
+		if marriage {
+			s := c.Suit
+			p.AnnouncedMarriage = &s
+		}
+		t.Plays = append(t.Plays, p)
+	}
+	r.Tricks = append(r.Tricks, t)
+	return nil
+}
@@ -0,0 +1,147 @@
+package handrecord
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+func TestWriteReadReplayRoundTrip(t *testing.T) {
+	players := []engine.PlayerID{"P1", "P2"}
+	var records []*Record
+	for seed := int64(1); seed <= 20; seed++ {
+		g := engine.NewGame(engine.GameParams{Players: players}, players[seed%2], players, map[engine.PlayerID]int{"P1": 120, "P2": -20})
+		if err := match.Deal(g, rand.New(rand.NewSource(seed))); err != nil {
+			t.Fatalf("deal: %v", err)
+		}
+		r := New(g)
+		r.Seed = &seed
+		bots := map[engine.PlayerID]player.Player{"P1": player.NewRandomBot(seed), "P2": player.NewRandomBot(seed + 1)}
+		if err := match.PlayHand(g, bots); err != nil {
+			t.Fatalf("play: %v", err)
+		}
+		r.Update(g)
+		records = append(records, r)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, records...); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	parsed, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(parsed) != len(records) {
+		t.Fatalf("expected %d records, got %d", len(records), len(parsed))
+	}
+	for i, r := range parsed {
+		if !reflect.DeepEqual(r, records[i]) {
+			t.Fatalf("record %d differs after round trip:\n%+v\n%+v", i, r, records[i])
+		}
+		g, err := r.Replay()
+		if err != nil {
+			t.Fatalf("replay %d: %v", i, err)
+		}
+		if g.Phase != engine.PhaseHandEnd {
+			t.Fatalf("replay %d ended in %v", i, g.Phase)
+		}
+		stepped, err := r.Start()
+		if err != nil {
+			t.Fatalf("start %d: %v", i, err)
+		}
+		for _, a := range r.Actions() {
+			if err := stepped.Apply(a); err != nil {
+				t.Fatalf("record %d: %v: %v", i, a, err)
+			}
+		}
+		if !reflect.DeepEqual(stepped.Scores, g.Scores) {
+			t.Fatalf("record %d: actions score %v, replay %v", i, stepped.Scores, g.Scores)
+		}
+	}
+}
+
+const marriageRecord = `; P2 declares hearts on the first lead
+[Players "P1 P2"]
+[Dealer "P1"]
+[Hand "P1:9S JS QS KS 10S AS 9C JC QC KC"]
+[Hand "P2:10C AC 9D JD QD KD 10D AD 9H JH"]
+[Musiks "QH KH / 10H AH"]
+[Auction "P1 100, P2 110, P1 pass"]
+[Musik "0"]
+[Discard "9D JD"]
+[Play]
+P2 KH+ P1 9S
+`
+
+func TestReadMarriageAndReplay(t *testing.T) {
+	records, err := Read(strings.NewReader(marriageRecord))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	g, err := records[0].Replay()
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if g.Play.Trump == nil || *g.Play.Trump != engine.Hearts {
+		t.Fatalf("expected hearts trump")
+	}
+	if g.Scores.DealPoints["P2"] != 100+4 {
+		t.Fatalf("expected 104 deal points for P2, got %d", g.Scores.DealPoints["P2"])
+	}
+}
+
+func TestReplayRejectsIllegalPlay(t *testing.T) {
+	records, err := Read(strings.NewReader(marriageRecord))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	records[0].Tricks = append(records[0].Tricks, engine.Trick{Plays: []engine.Play{{Player: "P1", Card: engine.Card{Suit: engine.Clubs, Rank: engine.Nine}}}})
+	if _, err := records[0].Replay(); err == nil {
+		t.Fatalf("expected out-of-turn play to fail replay")
+	}
+}
+
+func TestReplayDealsFromSeed(t *testing.T) {
+	players := []engine.PlayerID{"P1", "P2"}
+	seed := int64(42)
+	g := engine.NewGame(engine.GameParams{Players: players}, "P2", players, nil)
+	if err := match.Deal(g, rand.New(rand.NewSource(seed))); err != nil {
+		t.Fatal(err)
+	}
+	r := New(g)
+	r.Seed = &seed
+	bots := map[engine.PlayerID]player.Player{"P1": player.NewHeuristicBot(), "P2": player.NewHeuristicBot()}
+	if err := match.PlayHand(g, bots); err != nil {
+		t.Fatal(err)
+	}
+	r.Update(g)
+	r.Hands, r.Musiks = nil, nil
+
+	var buf bytes.Buffer
+	if err := Write(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "[Hand ") {
+		t.Fatalf("record written with hands:\n%s", buf.String())
+	}
+	parsed, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := parsed[0].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayed.Scores, g.Scores) {
+		t.Fatalf("replay scores %v, played %v", replayed.Scores, g.Scores)
+	}
+}
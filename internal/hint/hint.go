@@ -0,0 +1,332 @@
+// Package hint coaches human players: it asks a bot what it would do from a
+// player's view and backs the advice with sampled outcomes of every legal
+// action.
+package hint
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/estimate"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// Options configures Advise. Zero values get the defaults noted.
+type Options struct {
+	// Bot is the spec of the advising bot (default "ismcts").
+	Bot string
+	// Samples is the number of deals of the unseen cards every candidate
+	// action is played out on (default 50).
+	Samples int
+	// Candidates caps the actions evaluated (default 12). Discards are
+	// narrowed to the best by player.DiscardPlanner's static score; the
+	// bot's own choice is always evaluated.
+	Candidates int
+	Seed       int64
+}
+
+// Outcome is the sampled result of taking an action.
+type Outcome struct {
+	Action      engine.Action `json:"action"`
+	Description string        `json:"description"`
+	// ExpectedPoints is the mean change of the player's score minus the
+	// opponent's over the hand.
+	ExpectedPoints float64 `json:"expectedPoints"`
+	// WinProbability is the share of samples in which the player's side
+	// won the contract: made it as declarer or defeated it as defender.
+	WinProbability float64 `json:"winProbability"`
+}
+
+// Hint is the advice for one decision.
+type Hint struct {
+	Bot         string  `json:"bot"`
+	Recommended Outcome `json:"recommended"`
+	Explanation string  `json:"explanation"`
+	// Alternatives are the other evaluated actions, best first.
+	Alternatives []Outcome `json:"alternatives"`
+	Samples      int       `json:"samples"`
+	// Contract is set during the auction: how likely the player's hand is
+	// to make the next bids as declarer, from estimate.ContractOdds.
+	Contract []BidOdds `json:"contract,omitempty"`
+}
+
+// BidOdds is the share of sampled deals in which a hand makes Bid.
+type BidOdds struct {
+	Bid         int     `json:"bid"`
+	Probability float64 `json:"probability"`
+}
+
+// Advise returns the advising bot's action for view, which must be the view
+// of the player to act, with every candidate action played out by
+// HeuristicBot on the same sampled deals. A malformed view is an error, and
+// a searching bot stops early when ctx is done.
+func Advise(ctx context.Context, view engine.PlayerView, opts Options) (*Hint, error) {
+	if opts.Bot == "" {
+		opts.Bot = "ismcts"
+	}
+	if opts.Samples <= 0 {
+		opts.Samples = 50
+	}
+	if opts.Candidates <= 0 {
+		opts.Candidates = 12
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	// A determinization has the player's own cards exactly, so its legal
+	// actions are the player's.
+	g, err := rebuild(view, rng)
+	if err != nil {
+		return nil, err
+	}
+	if g.ActingPlayer() != view.Seat {
+		return nil, fmt.Errorf("%s is not to act in phase %v", view.Seat, view.Phase)
+	}
+	bot, err := player.NewFromSpec(opts.Bot, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+	inner := bot
+	if b, ok := bot.(*player.PersonalityBot); ok {
+		inner = b.Player
+	}
+	if b, ok := inner.(*player.ISMCTSBot); ok {
+		b.Done = ctx.Done()
+	}
+	choice, err := match.Decide(bot, view)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", bot.Name(), err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	outcomes, err := Evaluate(ctx, view, Candidates(view, g.LegalActions(), choice, opts.Candidates), opts.Samples, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Hint{Bot: bot.Name(), Recommended: outcomes[0], Alternatives: outcomes[1:], Samples: opts.Samples}
+	sort.SliceStable(h.Alternatives, func(i, j int) bool {
+		return h.Alternatives[i].ExpectedPoints > h.Alternatives[j].ExpectedPoints
+	})
+	if view.Phase == engine.PhaseAuction {
+		if h.Contract, err = contractOdds(view, g.HighestBid()+g.Params.MinRaise, opts.Samples, rng); err != nil {
+			return nil, err
+		}
+	}
+	h.Explanation = explain(h)
+	return h, nil
+}
+
+// rebuild determinizes view and validates the result, so that a malformed
+// view, say from a client, is an error rather than a panic in the bots.
+func rebuild(view engine.PlayerView, rng *rand.Rand) (*engine.GameState, error) {
+	if err := checkView(view); err != nil {
+		return nil, fmt.Errorf("invalid view: %w", err)
+	}
+	g := player.Determinize(view, rng)
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid view: %w", err)
+	}
+	// Determinize replays the auction, skipping illegal bids
+	if g.Phase != view.Phase || len(g.Auction.Bids) != len(view.Bids) {
+		return nil, fmt.Errorf("invalid view: the bids do not lead to the %v phase", view.Phase)
+	}
+	return g, nil
+}
+
+// checkView checks what Determinize relies on: the players, a deal of the
+// whole deck, an opened auction, a declarer once there is one and card
+// counts that leave enough unseen cards to deal out.
+func checkView(v engine.PlayerView) error {
+	p := v.Params
+	if len(p.Players) != 2 || p.Players[0] == p.Players[1] {
+		return fmt.Errorf("hints support 2 players")
+	}
+	if !slices.Contains(p.Players, v.Seat) || !slices.Contains(p.Players, v.Dealer) {
+		return fmt.Errorf("seat %q or dealer %q is not a player", v.Seat, v.Dealer)
+	}
+	if p.HandCards <= 0 || p.MusiksCount <= 0 || p.MusikSize <= 0 || 2*p.HandCards+p.MusiksCount*p.MusikSize != 24 {
+		return fmt.Errorf("params do not deal the 24-card deck")
+	}
+	if v.Phase < engine.PhaseAuction || v.Phase > engine.PhasePlay {
+		return fmt.Errorf("no decision in %v phase", v.Phase)
+	}
+	if len(v.Bids) == 0 {
+		return fmt.Errorf("no opening bid")
+	}
+	if v.Phase >= engine.PhaseTalonExchange && (v.Declarer == nil || !slices.Contains(p.Players, *v.Declarer)) {
+		return fmt.Errorf("no declarer in %v phase", v.Phase)
+	}
+
+	seen := map[engine.Card]bool{}
+	for _, c := range append(append([]engine.Card{}, v.Hand...), v.OwnDiscards...) {
+		seen[c] = true
+	}
+	for _, t := range append(append([]engine.Trick{}, v.CompletedTricks...), v.CurrentTrick) {
+		for _, pl := range t.Plays {
+			seen[pl.Card] = true
+		}
+	}
+	if v.Phase < engine.PhasePlay {
+		want := p.HandCards
+		if v.Phase == engine.PhaseTalonExchange && v.MusikCount == 0 && *v.Declarer == v.Seat {
+			want += p.MusikSize
+		}
+		if len(v.Hand) != want || len(seen) != want {
+			return fmt.Errorf("hand of %d distinct cards in %v phase, want %d", len(seen), v.Phase, want)
+		}
+		return nil
+	}
+	if n := v.HandSizes[v.Others()[0]]; n < 0 || n > 24-len(seen) {
+		return fmt.Errorf("opponent holds %d cards, %d are unseen", n, 24-len(seen))
+	}
+	for i, t := range v.CompletedTricks {
+		if t.WinningPlayIndex < 0 || t.WinningPlayIndex >= len(t.Plays) {
+			return fmt.Errorf("trick %d has no winning play", i+1)
+		}
+	}
+	return nil
+}
+
+// Evaluate plays each action out from view on the same samples deals of the
+// unseen cards, finishing the hand with HeuristicBot on both sides, and
+// returns the outcomes in the order of actions.
+func Evaluate(ctx context.Context, view engine.PlayerView, actions []engine.Action, samples int, rng *rand.Rand) ([]Outcome, error) {
+	outcomes := make([]Outcome, len(actions))
+	for i, a := range actions {
+		outcomes[i] = Outcome{Action: a, Description: a.String()}
+	}
+	for range samples {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		root := player.Determinize(view, rng)
+		for i := range outcomes {
+			swing, won, err := playOut(root.Clone(), outcomes[i].Action, view)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", outcomes[i].Action, err)
+			}
+			outcomes[i].ExpectedPoints += swing
+			if won {
+				outcomes[i].WinProbability++
+			}
+		}
+	}
+	for i := range outcomes {
+		outcomes[i].ExpectedPoints /= float64(samples)
+		outcomes[i].WinProbability /= float64(samples)
+	}
+	return outcomes, nil
+}
+
+// contractOdds estimates how likely the player's hand is to make from bid
+// upwards, in raises of MinRaise, stopping at the first bid never made.
+func contractOdds(view engine.PlayerView, bid, samples int, rng *rand.Rand) ([]BidOdds, error) {
+	params := view.Params
+	params.Players = []engine.PlayerID{view.Seat, view.Others()[0]}
+	dist, err := estimate.ContractOdds(view.Hand, params, estimate.Options{Samples: samples, Rand: rng})
+	if err != nil {
+		return nil, err
+	}
+	var out []BidOdds
+	for ; len(out) < 5; bid += params.MinRaise {
+		out = append(out, BidOdds{Bid: bid, Probability: dist.AtLeast(bid)})
+		if out[len(out)-1].Probability == 0 {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Candidates returns first followed by up to limit-1 other actions from
+// legal. Discards are taken in the order of player.DiscardPlanner's static
+// score, so the plausible ones survive the limit.
+func Candidates(view engine.PlayerView, legal []engine.Action, first engine.Action, limit int) []engine.Action {
+	out := []engine.Action{first}
+	if len(legal) > 0 && legal[0].Type == engine.ActionDiscard {
+		legal = nil
+		var planner player.DiscardPlanner
+		for _, o := range planner.Plan(view) {
+			legal = append(legal, engine.Action{Type: engine.ActionDiscard, Player: view.Seat, Cards: o.Cards})
+		}
+	}
+	for _, a := range legal {
+		if len(out) == limit {
+			break
+		}
+		if !SameAction(a, first) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// SameAction reports whether a and b are the same decision, ignoring the
+// order of discarded cards.
+func SameAction(a, b engine.Action) bool {
+	if a.Type != b.Type || a.Value != b.Value || a.Index != b.Index || a.Card != b.Card || a.Marriage != b.Marriage {
+		return false
+	}
+	if len(a.Cards) != len(b.Cards) {
+		return false
+	}
+	for _, c := range a.Cards {
+		found := false
+		for _, d := range b.Cards {
+			found = found || c == d
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// playOut applies a to g and finishes the hand with HeuristicBot on both
+// sides. It returns the player's score swing over the opponent and whether
+// the player's side won the contract.
+func playOut(g *engine.GameState, a engine.Action, view engine.PlayerView) (float64, bool, error) {
+	if err := g.Apply(a); err != nil {
+		return 0, false, err
+	}
+	bots := map[engine.PlayerID]player.Player{}
+	for _, p := range g.Params.Players {
+		bots[p] = player.NewHeuristicBot()
+	}
+	if err := match.PlayHand(g, bots); err != nil {
+		return 0, false, err
+	}
+	me, opp := view.Seat, view.Others()[0]
+	swing := g.Scores.Cumulative[me] - view.Cumulative[me] - (g.Scores.Cumulative[opp] - view.Cumulative[opp])
+	made := g.Scores.DealPoints[*g.Declarer] >= g.HighestBid()
+	return float64(swing), made == (*g.Declarer == me), nil
+}
+
+// explain summarises the hint in a sentence or two.
+func explain(h *Hint) string {
+	r := h.Recommended
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s would %s: %+.0f points on average over %d deals of the unseen cards, winning %.0f%% of them.",
+		h.Bot, r.Description, r.ExpectedPoints, h.Samples, 100*r.WinProbability)
+	if len(h.Contract) > 0 {
+		c := h.Contract[0]
+		fmt.Fprintf(&b, " As declarer the hand makes %d in %.0f%% of deals.", c.Bid, 100*c.Probability)
+	}
+	if len(h.Alternatives) == 0 {
+		b.WriteString(" It is the only legal move.")
+		return b.String()
+	}
+	alt := h.Alternatives[0]
+	if alt.ExpectedPoints > r.ExpectedPoints {
+		fmt.Fprintf(&b, " Simple play-outs prefer to %s (%+.0f), but they play both sides by rules of thumb, so trust the bot's deeper look.",
+			alt.Description, alt.ExpectedPoints)
+	} else {
+		fmt.Fprintf(&b, " The best alternative, %s, averages %+.0f.", alt.Description, alt.ExpectedPoints)
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package hint
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// playView deals a hand, plays it with HeuristicBots until the second
+// trick and returns the game.
+func playView(t *testing.T) *engine.GameState {
+	t.Helper()
+	players := []engine.PlayerID{"P1", "P2"}
+	g := engine.NewGame(engine.GameParams{}, "P1", players, nil)
+	if err := match.Deal(g, rand.New(rand.NewSource(7))); err != nil {
+		t.Fatal(err)
+	}
+	bot := player.NewHeuristicBot()
+	for g.Phase != engine.PhasePlay || len(g.Play.CompletedTricks) < 1 {
+		a, err := match.Decide(bot, g.View(g.ActingPlayer()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Apply(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g
+}
+
+func TestAdviseRanksLegalActions(t *testing.T) {
+	g := playView(t)
+	p := g.ActingPlayer()
+	// the view travels as JSON over HTTP
+	b, err := json.Marshal(g.View(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var view engine.PlayerView
+	if err := json.Unmarshal(b, &view); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := Advise(context.Background(), view, Options{Bot: "heuristic", Samples: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legal := g.LegalActions()
+	if got := 1 + len(h.Alternatives); got != len(legal) {
+		t.Fatalf("evaluated %d actions, want all %d legal ones", got, len(legal))
+	}
+	for _, o := range append([]Outcome{h.Recommended}, h.Alternatives...) {
+		found := false
+		for _, a := range legal {
+			found = found || SameAction(a, o.Action)
+		}
+		if !found {
+			t.Errorf("%s is not legal", o.Description)
+		}
+		if o.WinProbability < 0 || o.WinProbability > 1 {
+			t.Errorf("%s: win probability %v", o.Description, o.WinProbability)
+		}
+	}
+	for i := 1; i < len(h.Alternatives); i++ {
+		if h.Alternatives[i].ExpectedPoints > h.Alternatives[i-1].ExpectedPoints {
+			t.Fatalf("alternatives not ranked: %+v", h.Alternatives)
+		}
+	}
+	if h.Explanation == "" {
+		t.Error("no explanation")
+	}
+
+	other := g.View(view.Others()[0])
+	if _, err := Advise(context.Background(), other, Options{Bot: "heuristic", Samples: 1}); err == nil {
+		t.Error("advised a player who is not to act")
+	}
+}
+
+func TestAdviseNarrowsDiscards(t *testing.T) {
+	players := []engine.PlayerID{"P1", "P2"}
+	g := engine.NewGame(engine.GameParams{}, "P1", players, nil)
+	if err := match.Deal(g, rand.New(rand.NewSource(3))); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []engine.Action{
+		{Type: engine.ActionBid, Player: "P2", Value: 0},
+		{Type: engine.ActionChooseMusik, Player: "P1", Index: 0},
+	} {
+		if err := g.Apply(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h, err := Advise(context.Background(), g.View("P1"), Options{Bot: "heuristic", Samples: 5, Candidates: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Recommended.Action.Type != engine.ActionDiscard || len(h.Alternatives) != 3 {
+		t.Fatalf("got %s and %d alternatives", h.Recommended.Description, len(h.Alternatives))
+	}
+}
+
+func TestAdviseRejectsMalformedViews(t *testing.T) {
+	g := playView(t)
+	good := g.View(g.ActingPlayer())
+	for name, edit := range map[string]func(v *engine.PlayerView){
+		"no bids":        func(v *engine.PlayerView) { v.Bids = nil },
+		"no declarer":    func(v *engine.PlayerView) { v.Declarer = nil },
+		"one player":     func(v *engine.PlayerView) { v.Params.Players = v.Params.Players[:1] },
+		"short deal":     func(v *engine.PlayerView) { v.Params.HandCards = 5 },
+		"hidden hand":    func(v *engine.PlayerView) { v.HandSizes[v.Others()[0]] = 20 },
+		"extra card":     func(v *engine.PlayerView) { v.Hand = append(v.Hand, v.CompletedTricks[0].Plays[0].Card) },
+		"lost winner":    func(v *engine.PlayerView) { v.CompletedTricks[0].WinningPlayIndex = 5 },
+		"dealing phase":  func(v *engine.PlayerView) { v.Phase = engine.PhaseDeal },
+		"auction replay": func(v *engine.PlayerView) { v.Phase = engine.PhaseAuction },
+	} {
+		view := g.View(g.ActingPlayer())
+		edit(&view)
+		if _, err := Advise(context.Background(), view, Options{Bot: "heuristic", Samples: 1}); err == nil {
+			t.Errorf("%s: advised on a malformed view", name)
+		}
+	}
+	if _, err := Advise(context.Background(), good, Options{Bot: "heuristic", Samples: 1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdviseStopsWhenCancelled(t *testing.T) {
+	g := playView(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Advise(ctx, g.View(g.ActingPlayer()), Options{Bot: "ismcts:1h", Samples: 1}); err != context.Canceled {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAdviseEstimatesContracts(t *testing.T) {
+	g := engine.NewGame(engine.GameParams{}, "P1", []engine.PlayerID{"P1", "P2"}, nil)
+	if err := match.Deal(g, rand.New(rand.NewSource(5))); err != nil {
+		t.Fatal(err)
+	}
+	h, err := Advise(context.Background(), g.View("P2"), Options{Bot: "heuristic", Samples: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Contract) == 0 || h.Contract[0].Bid != 110 {
+		t.Fatalf("contract odds %+v, want them from 110", h.Contract)
+	}
+	for i := 1; i < len(h.Contract); i++ {
+		if h.Contract[i].Probability > h.Contract[i-1].Probability {
+			t.Fatalf("higher bid made more often: %+v", h.Contract)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// HandResult summarises one scored hand.
+type HandResult struct {
+	Dealer   engine.PlayerID
+	Declarer engine.PlayerID
+	Bid      int
+	// Made reports whether the declarer reached the bid.
+	Made       bool
+	DealPoints map[engine.PlayerID]int
+	// Delta is each player's change in cumulative score.
+	Delta map[engine.PlayerID]int
+	// Marriages lists the suits of the marriages announced, in play order.
+	Marriages []engine.Suit
+}
+
+// GameResult summarises a game played to MaxGamePoints.
+type GameResult struct {
+	Hands      []HandResult
+	Cumulative map[engine.PlayerID]int
+	// Winner is empty when the game hit the hand limit or ended level.
+	Winner engine.PlayerID
+}
+
+// PlayGame plays hands until a player reaches params.MaxGamePoints or
+// maxHands hands have been played (zero means no limit). The deal rotates
+// through players starting with the first, and rng shuffles every deck.
+// The player with the higher score wins when both cross the line together.
+// The runner's OnDealt and OnScored hooks see every hand.
+func (r *Runner) PlayGame(ctx context.Context, params engine.GameParams, players []engine.PlayerID, bots map[engine.PlayerID]player.Player, rng *rand.Rand, maxHands int) (*GameResult, error) {
+	res := &GameResult{Cumulative: map[engine.PlayerID]int{}}
+	for _, p := range players {
+		res.Cumulative[p] = 0
+	}
+	target := 0
+	for n := 0; maxHands == 0 || n < maxHands; n++ {
+		dealer := players[n%len(players)]
+		g := engine.NewGame(params, dealer, players, res.Cumulative)
+		target = g.Params.MaxGamePoints
+		if err := Deal(g, rng); err != nil {
+			return nil, err
+		}
+		if r.OnDealt != nil {
+			r.OnDealt(g)
+		}
+		if err := r.PlayHand(ctx, g, bots); err != nil {
+			return nil, fmt.Errorf("hand %d: %w", n+1, err)
+		}
+		if r.OnScored != nil {
+			r.OnScored(g)
+		}
+		hr := Result(g, res.Cumulative)
+		res.Hands = append(res.Hands, hr)
+		res.Cumulative = g.Scores.Cumulative
+		if over, _ := g.IsWinningGame(); over {
+			break
+		}
+	}
+	var leaders []engine.PlayerID
+	best := 0
+	for _, p := range players {
+		switch c := res.Cumulative[p]; {
+		case len(leaders) == 0 || c > best:
+			best, leaders = c, []engine.PlayerID{p}
+		case c == best:
+			leaders = append(leaders, p)
+		}
+	}
+	if len(leaders) == 1 && best >= target {
+		res.Winner = leaders[0]
+	}
+	return res, nil
+}
+
+// Result summarises the scored hand g; start holds the cumulative scores
+// before the hand, nil meaning zero.
+func Result(g *engine.GameState, start map[engine.PlayerID]int) HandResult {
+	hr := HandResult{
+		Dealer:     g.Dealer,
+		Declarer:   *g.Declarer,
+		Bid:        g.HighestBid(),
+		Made:       g.Scores.DealPoints[*g.Declarer] >= g.HighestBid(),
+		DealPoints: g.Scores.DealPoints,
+		Delta:      map[engine.PlayerID]int{},
+	}
+	for _, p := range g.Params.Players {
+		hr.Delta[p] = g.Scores.Cumulative[p] - start[p]
+	}
+	for _, t := range g.Play.CompletedTricks {
+		for _, p := range t.Plays {
+			if p.AnnouncedMarriage != nil {
+				hr.Marriages = append(hr.Marriages, *p.AnnouncedMarriage)
+			}
+		}
+	}
+	return hr
+}
@@ -0,0 +1,137 @@
+// Package match drives engine games with player.Player implementations.
+package match
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// NewDeck builds the 24-card deck ordered by suit, then rank.
+func NewDeck() []engine.Card {
+	deck := make([]engine.Card, 0, 24)
+	for _, s := range []engine.Suit{engine.Spades, engine.Hearts, engine.Diamonds, engine.Clubs} {
+		for _, r := range engine.RankOrder {
+			deck = append(deck, engine.Card{Suit: s, Rank: r})
+		}
+	}
+	return deck
+}
+
+// Shuffle shuffles cards in place using rng.
+func Shuffle(cards []engine.Card, rng *rand.Rand) {
+	rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+}
+
+// maxRedeals bounds the redeals of one hand, so rules that call for a redeal
+// too often fail instead of dealing forever.
+const maxRedeals = 100
+
+// Deal shuffles a fresh deck with rng and deals it into g, dealing again
+// while the rules call for a redeal.
+func Deal(g *engine.GameState, rng *rand.Rand) error {
+	for range maxRedeals {
+		deck := NewDeck()
+		Shuffle(deck, rng)
+		if err := DealDeck(g, deck); err != nil {
+			return err
+		}
+		if !g.RedealDue() {
+			return nil
+		}
+		if err := g.Redeal(); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("no deal in %d that the rules keep", maxRedeals)
+}
+
+// DealDeck deals deck in order: one hand per player, then the musiks. It
+// fails when the deck is too short for the deal.
+func DealDeck(g *engine.GameState, deck []engine.Card) error {
+	p := g.Params
+	if p.HandCards < 0 || p.MusiksCount < 0 || p.MusikSize < 0 {
+		return fmt.Errorf("invalid deal of %d cards per hand and %d musiks of %d", p.HandCards, p.MusiksCount, p.MusikSize)
+	}
+	if need := len(p.Players)*p.HandCards + p.MusiksCount*p.MusikSize; len(deck) < need {
+		return fmt.Errorf("deck of %d cards, the deal needs %d", len(deck), need)
+	}
+	hands := map[engine.PlayerID][]engine.Card{}
+	n := 0
+	for _, p := range g.Params.Players {
+		hands[p] = append([]engine.Card{}, deck[n:n+g.Params.HandCards]...)
+		n += g.Params.HandCards
+	}
+	musiks := make([][]engine.Card, g.Params.MusiksCount)
+	for i := range musiks {
+		musiks[i] = append([]engine.Card{}, deck[n:n+g.Params.MusikSize]...)
+		n += g.Params.MusikSize
+	}
+	return g.SetDealtCards(hands, musiks)
+}
+
+// PlayHand asks bots for every decision until the hand reaches HandEnd.
+// It may be called in any phase after the deal; bots get OnHandStart when it
+// begins, OnEvent after every action and OnHandEnd once the hand is scored.
+// It stops at the first bot error; use a Runner to supervise untrusted bots.
+func PlayHand(g *engine.GameState, bots map[engine.PlayerID]player.Player) error {
+	var r Runner
+	return r.PlayHand(context.Background(), g, bots)
+}
+
+// Decide asks bot for the decision the view's phase calls for.
+func Decide(bot player.Player, view engine.PlayerView) (engine.Action, error) {
+	a := engine.Action{Player: view.Seat}
+	var err error
+	switch {
+	case view.Phase == engine.PhaseAuction:
+		a.Type = engine.ActionBid
+		if a.Value, err = bot.MakeBidDecision(view); err != nil {
+			return a, fmt.Errorf("bid: %w", err)
+		}
+	case view.Phase == engine.PhaseTalonExchange && view.MusikCount > 0:
+		a.Type = engine.ActionChooseMusik
+		if a.Index, err = bot.ChooseMusik(view); err != nil {
+			return a, fmt.Errorf("choose musik: %w", err)
+		}
+	case view.Phase == engine.PhaseTalonExchange:
+		a.Type = engine.ActionDiscard
+		if a.Cards, err = bot.ChooseDiscardCards(view); err != nil {
+			return a, fmt.Errorf("choose discard cards: %w", err)
+		}
+	case view.Phase == engine.PhasePlay:
+		a.Type = engine.ActionPlayCard
+		if a.Card, a.Marriage, err = bot.PlayCard(view); err != nil {
+			return a, fmt.Errorf("play card: %w", err)
+		}
+	default:
+		return a, fmt.Errorf("no decision in %v phase", view.Phase)
+	}
+	return a, nil
+}
+
+// PlayerIDs derives unique player IDs from bot names, numbering repeated
+// names in order: "RandomBot", "RandomBot#2".
+func PlayerIDs(bots ...player.Player) []engine.PlayerID {
+	ids := make([]engine.PlayerID, len(bots))
+	used := map[engine.PlayerID]bool{}
+	next := map[string]int{}
+	for i, b := range bots {
+		name := b.Name()
+		id := engine.PlayerID(name)
+		// a bot may already be named like a numbered duplicate
+		for used[id] {
+			if next[name] == 0 {
+				next[name] = 2
+			}
+			id = engine.PlayerID(fmt.Sprintf("%s#%d", name, next[name]))
+			next[name]++
+		}
+		used[id] = true
+		ids[i] = id
+	}
+	return ids
+}
@@ -0,0 +1,254 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// FallbackPolicy decides what happens when a bot fails to make a legal decision.
+type FallbackPolicy int
+
+const (
+	// FallbackError stops the hand and returns the bot's error.
+	FallbackError FallbackPolicy = iota
+	// FallbackRandom replaces the decision with a random legal action.
+	FallbackRandom
+	// FallbackForfeit stops the hand with a *ForfeitError naming the offender.
+	FallbackForfeit
+)
+
+// OffenceKind classifies bot misbehaviour.
+type OffenceKind string
+
+const (
+	OffenceTimeout OffenceKind = "timeout"
+	OffencePanic   OffenceKind = "panic"
+	OffenceError   OffenceKind = "error"
+	OffenceIllegal OffenceKind = "illegal move"
+)
+
+// Offence records one bot failure.
+type Offence struct {
+	Player engine.PlayerID
+	Bot    string
+	Kind   OffenceKind
+	Phase  engine.Phase
+	Detail string
+}
+
+func (o Offence) String() string {
+	return fmt.Sprintf("%s (%s) %s in %v phase: %s", o.Player, o.Bot, o.Kind, o.Phase, o.Detail)
+}
+
+// ForfeitError is returned when a bot forfeits the hand under FallbackForfeit.
+type ForfeitError struct {
+	Offence Offence
+}
+
+func (e *ForfeitError) Error() string { return "forfeit: " + e.Offence.String() }
+
+// Runner plays hands while supervising bots: every call gets a deadline,
+// panics are recovered and failed decisions are handled by Fallback. A bot
+// that times out keeps running in its goroutine, so the runner never calls
+// it again, in this hand or any later one, and the fallback decides for it.
+// Bots are told apart by identity, so they should be pointers. A Runner may
+// be shared by concurrent hands.
+type Runner struct {
+	// Timeout bounds every bot call; zero means no deadline, and bots are
+	// then called directly.
+	Timeout  time.Duration
+	Fallback FallbackPolicy
+	// Rand drives FallbackRandom; a fixed seed is used when nil.
+	Rand *rand.Rand
+	// OnDealt and OnScored, if set, are called by PlayGame with each hand
+	// once it is dealt and once it is scored, say to record it.
+	OnDealt, OnScored func(*engine.GameState)
+
+	mu       sync.Mutex
+	offences []Offence
+	hung     map[player.Player]bool
+}
+
+// Offences returns every offence recorded so far.
+func (r *Runner) Offences() []Offence {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Offence(nil), r.offences...)
+}
+
+// OffenceCounts tallies offences per bot name and kind.
+func (r *Runner) OffenceCounts() map[string]map[OffenceKind]int {
+	out := map[string]map[OffenceKind]int{}
+	for _, o := range r.Offences() {
+		if out[o.Bot] == nil {
+			out[o.Bot] = map[OffenceKind]int{}
+		}
+		out[o.Bot][o.Kind]++
+	}
+	return out
+}
+
+func (r *Runner) record(o Offence) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offences = append(r.offences, o)
+}
+
+func (r *Runner) randomAction(g *engine.GameState) engine.Action {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Rand == nil {
+		r.Rand = rand.New(rand.NewSource(1))
+	}
+	legal := g.LegalActions()
+	return legal[r.Rand.Intn(len(legal))]
+}
+
+// isHung reports whether bot has timed out under this runner.
+func (r *Runner) isHung(bot player.Player) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hung[bot]
+}
+
+// quarantine stops the runner from calling bot again.
+func (r *Runner) quarantine(bot player.Player) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hung == nil {
+		r.hung = map[player.Player]bool{}
+	}
+	r.hung[bot] = true
+}
+
+// call runs fn under the runner's deadline and recovers a panic. Without a
+// timeout fn runs on the caller's goroutine.
+func (r *Runner) call(ctx context.Context, fn func() error) (OffenceKind, error) {
+	if r.Timeout <= 0 {
+		return protect(fn)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	type result struct {
+		kind OffenceKind
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		kind, err := protect(fn)
+		done <- result{kind, err}
+	}()
+	select {
+	case res := <-done:
+		return res.kind, res.err
+	case <-ctx.Done():
+		return OffenceTimeout, ctx.Err()
+	}
+}
+
+// protect runs fn, turning a panic into an error.
+func protect(fn func() error) (kind OffenceKind, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			kind, err = OffencePanic, fmt.Errorf("%v", p)
+		}
+	}()
+	if err := fn(); err != nil {
+		return OffenceError, err
+	}
+	return "", nil
+}
+
+// seat tracks one bot during a hand.
+type seat struct {
+	bot  player.Player
+	name string
+}
+
+// PlayHand asks bots for every decision until the hand reaches HandEnd,
+// like the package-level PlayHand, applying the runner's supervision.
+func (r *Runner) PlayHand(ctx context.Context, g *engine.GameState, bots map[engine.PlayerID]player.Player) error {
+	seats := map[engine.PlayerID]*seat{}
+	for _, p := range g.Params.Players {
+		seats[p] = &seat{bot: bots[p], name: bots[p].Name()}
+	}
+	// hook runs a lifecycle hook; failures are recorded but never fatal
+	hook := func(p engine.PlayerID, fn func(player.Player)) {
+		s := seats[p]
+		if r.isHung(s.bot) {
+			return
+		}
+		if kind, err := r.call(ctx, func() error { fn(s.bot); return nil }); err != nil {
+			r.record(Offence{Player: p, Bot: s.name, Kind: kind, Phase: g.Phase, Detail: err.Error()})
+			if kind == OffenceTimeout {
+				r.quarantine(s.bot)
+			}
+		}
+	}
+	for _, p := range g.Params.Players {
+		view := g.View(p)
+		hook(p, func(b player.Player) { b.OnHandStart(view) })
+	}
+	for g.Phase != engine.PhaseHandEnd {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := g.ActingPlayer()
+		if p == "" {
+			return fmt.Errorf("hand stopped in %v phase", g.Phase)
+		}
+		ev, err := r.step(ctx, g, p, seats[p])
+		if err != nil {
+			return err
+		}
+		for _, q := range g.Params.Players {
+			e := ev.For(q)
+			hook(q, func(b player.Player) { b.OnEvent(e) })
+		}
+	}
+	for _, p := range g.Params.Players {
+		view := g.View(p)
+		hook(p, func(b player.Player) { b.OnHandEnd(view) })
+	}
+	return nil
+}
+
+// step obtains and applies one decision from s, falling back on failure.
+func (r *Runner) step(ctx context.Context, g *engine.GameState, p engine.PlayerID, s *seat) (engine.Event, error) {
+	offence := Offence{Player: p, Bot: s.name, Phase: g.Phase, Kind: OffenceTimeout,
+		Detail: "timed out earlier"}
+	if !r.isHung(s.bot) {
+		var a engine.Action
+		view := g.View(p)
+		kind, err := r.call(ctx, func() error {
+			var err error
+			a, err = Decide(s.bot, view)
+			return err
+		})
+		if err == nil {
+			ev, stepErr := g.Step(a)
+			if stepErr == nil {
+				return ev, nil
+			}
+			kind, err = OffenceIllegal, fmt.Errorf("%s: %w", a.Type, stepErr)
+		}
+		if kind == OffenceTimeout {
+			r.quarantine(s.bot)
+		}
+		offence.Kind, offence.Detail = kind, err.Error()
+		r.record(offence)
+		if r.Fallback == FallbackError {
+			return engine.Event{}, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	if r.Fallback == FallbackForfeit {
+		return engine.Event{}, &ForfeitError{Offence: offence}
+	}
+	return g.Step(r.randomAction(g))
+}
@@ -0,0 +1,151 @@
+package match
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// faultyBot misbehaves on PlayCard according to mode. A hanging bot blocks
+// until release is closed.
+type faultyBot struct {
+	player.Player
+	mode    string
+	release chan struct{}
+}
+
+func (b *faultyBot) Name() string { return "faulty_" + b.mode }
+
+func (b *faultyBot) PlayCard(v engine.PlayerView) (engine.Card, bool, error) {
+	switch b.mode {
+	case "panic":
+		panic("boom")
+	case "hang":
+		<-b.release
+	case "illegal":
+		return engine.Card{Suit: engine.Spades, Rank: engine.Rank(99)}, false, nil
+	}
+	return engine.Card{}, false, errors.New("no idea")
+}
+
+func newTestHand(t *testing.T, mode string) (*engine.GameState, map[engine.PlayerID]player.Player) {
+	t.Helper()
+	players := []engine.PlayerID{"P1", "P2"}
+	params := engine.GameParams{Players: players, MinBid: 100, MinRaise: 10, HandCards: 10, MusiksCount: 2, MusikSize: 2}
+	g := engine.NewGame(params, "P1", players, nil)
+	if err := Deal(g, rand.New(rand.NewSource(7))); err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	bots := map[engine.PlayerID]player.Player{
+		"P1": player.NewHeuristicBot(),
+		"P2": &faultyBot{Player: player.NewHeuristicBot(), mode: mode, release: release},
+	}
+	return g, bots
+}
+
+func TestRunnerFallsBackToRandomMoves(t *testing.T) {
+	for _, tc := range []struct {
+		mode string
+		kind OffenceKind
+	}{
+		{"panic", OffencePanic},
+		{"hang", OffenceTimeout},
+		{"illegal", OffenceIllegal},
+		{"error", OffenceError},
+	} {
+		t.Run(tc.mode, func(t *testing.T) {
+			g, bots := newTestHand(t, tc.mode)
+			r := &Runner{Timeout: 20 * time.Millisecond, Fallback: FallbackRandom, Rand: rand.New(rand.NewSource(1))}
+			if err := r.PlayHand(context.Background(), g, bots); err != nil {
+				t.Fatal(err)
+			}
+			if g.Phase != engine.PhaseHandEnd {
+				t.Fatalf("phase = %v, want HandEnd", g.Phase)
+			}
+			counts := r.OffenceCounts()["faulty_"+tc.mode]
+			if counts[tc.kind] == 0 || len(counts) != 1 {
+				t.Fatalf("offences = %v, want only %q", counts, tc.kind)
+			}
+			// a hung bot is recorded once and then never called again,
+			// not even in the next hand
+			if tc.kind == OffenceTimeout {
+				next, _ := newTestHand(t, tc.mode)
+				if err := r.PlayHand(context.Background(), next, bots); err != nil {
+					t.Fatal(err)
+				}
+				if n := r.OffenceCounts()["faulty_"+tc.mode][tc.kind]; n != 1 {
+					t.Fatalf("timeouts = %d, want 1", n)
+				}
+			}
+		})
+	}
+}
+
+func TestRunnerForfeit(t *testing.T) {
+	g, bots := newTestHand(t, "panic")
+	r := &Runner{Fallback: FallbackForfeit}
+	err := r.PlayHand(context.Background(), g, bots)
+	var forfeit *ForfeitError
+	if !errors.As(err, &forfeit) {
+		t.Fatalf("err = %v, want *ForfeitError", err)
+	}
+	if forfeit.Offence.Player != "P2" || forfeit.Offence.Kind != OffencePanic {
+		t.Fatalf("offence = %+v", forfeit.Offence)
+	}
+}
+
+func TestPlayHandStopsOnBotError(t *testing.T) {
+	g, bots := newTestHand(t, "illegal")
+	if err := PlayHand(g, bots); err == nil {
+		t.Fatal("expected an error for an illegal card")
+	}
+}
+
+func TestDealDeckNeedsFullDeck(t *testing.T) {
+	players := []engine.PlayerID{"P1", "P2", "P3"}
+	g := engine.NewGame(engine.GameParams{}, "P1", players, nil)
+	if err := DealDeck(g, NewDeck()); err == nil {
+		t.Fatal("dealt three hands and two musiks from 24 cards")
+	}
+}
+
+func TestPlayerIDsAreUnique(t *testing.T) {
+	named := func(name string) player.Player { return &player.HeuristicBot{BotName: name} }
+	got := PlayerIDs(named("A"), named("A"), named("A#2"), named("A"))
+	want := []engine.PlayerID{"A", "A#2", "A#2#2", "A#3"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("PlayerIDs = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestVariantHandsPlay(t *testing.T) {
+	players := []engine.PlayerID{"P1", "P2"}
+	params := engine.GameParams{MaxBidWithoutMarriage: 120, NoMarriageOnFirstTrick: true, ScoreRounding: 10,
+		RedealFourNines: true, RedealBelow: 30, BarrelAt: 880}
+	rng := rand.New(rand.NewSource(5))
+	for i := range 20 {
+		g := engine.NewGame(params, players[i%2], players, nil)
+		if err := Deal(g, rng); err != nil {
+			t.Fatal(err)
+		}
+		if g.RedealDue() {
+			t.Fatalf("hand %d: dealt a hand the rules redeal", i+1)
+		}
+		bots := map[engine.PlayerID]player.Player{"P1": player.NewHeuristicBot(), "P2": player.NewRandomBot(int64(i))}
+		if err := PlayHand(g, bots); err != nil {
+			t.Fatalf("hand %d: %v", i+1, err)
+		}
+		if err := g.Validate(); err != nil {
+			t.Fatalf("hand %d: %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package nn is a small pure-Go multilayer perceptron with backpropagation
+// and the Adam optimiser, enough to train bot policies on a CPU.
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+)
+
+// MLP is a fully connected network with ReLU hidden layers and a linear
+// output layer. Forward passes do not modify the network, so one MLP can be
+// evaluated from several goroutines while nobody trains it.
+type MLP struct {
+	// Sizes lists the layer widths from input to output.
+	Sizes []int
+	// Weights[l] is row-major, Sizes[l+1] rows of Sizes[l] inputs.
+	Weights [][]float64
+	Biases  [][]float64
+}
+
+// New returns an MLP with He-initialised weights drawn from rng.
+func New(rng *rand.Rand, sizes ...int) *MLP {
+	m := &MLP{Sizes: append([]int{}, sizes...)}
+	for l := 0; l+1 < len(sizes); l++ {
+		in, out := sizes[l], sizes[l+1]
+		w := make([]float64, in*out)
+		scale := math.Sqrt(2 / float64(in))
+		for i := range w {
+			w[i] = rng.NormFloat64() * scale
+		}
+		m.Weights = append(m.Weights, w)
+		m.Biases = append(m.Biases, make([]float64, out))
+	}
+	return m
+}
+
+// Trace holds the activations of one forward pass for Backward.
+type Trace struct {
+	acts [][]float64 // acts[0] is the input, acts[l+1] the output of layer l
+}
+
+// Output returns the network output of the pass.
+func (t *Trace) Output() []float64 { return t.acts[len(t.acts)-1] }
+
+// Forward evaluates the network on x.
+func (m *MLP) Forward(x []float64) *Trace {
+	if len(x) != m.Sizes[0] {
+		panic(fmt.Sprintf("nn: input has %d values, want %d", len(x), m.Sizes[0]))
+	}
+	t := &Trace{acts: [][]float64{x}}
+	for l, w := range m.Weights {
+		in, out := m.Sizes[l], m.Sizes[l+1]
+		prev := t.acts[l]
+		next := make([]float64, out)
+		for o := 0; o < out; o++ {
+			sum := m.Biases[l][o]
+			row := w[o*in : (o+1)*in]
+			for i, v := range prev {
+				sum += row[i] * v
+			}
+			if l+1 < len(m.Weights) && sum < 0 {
+				sum = 0
+			}
+			next[o] = sum
+		}
+		t.acts = append(t.acts, next)
+	}
+	return t
+}
+
+// Grads accumulates gradients with the shape of an MLP.
+type Grads struct {
+	Weights [][]float64
+	Biases  [][]float64
+}
+
+// NewGrads returns zeroed gradients for m.
+func (m *MLP) NewGrads() *Grads {
+	g := &Grads{}
+	for l := range m.Weights {
+		g.Weights = append(g.Weights, make([]float64, len(m.Weights[l])))
+		g.Biases = append(g.Biases, make([]float64, len(m.Biases[l])))
+	}
+	return g
+}
+
+// Zero resets the gradients.
+func (g *Grads) Zero() {
+	for l := range g.Weights {
+		clear(g.Weights[l])
+		clear(g.Biases[l])
+	}
+}
+
+// Backward adds to g the gradient of the loss for the pass t, given the
+// loss gradient dOut with respect to the network output.
+func (m *MLP) Backward(t *Trace, dOut []float64, g *Grads) {
+	delta := append([]float64{}, dOut...)
+	for l := len(m.Weights) - 1; l >= 0; l-- {
+		in := m.Sizes[l]
+		prev := t.acts[l]
+		w := m.Weights[l]
+		var back []float64
+		if l > 0 {
+			back = make([]float64, in)
+		}
+		for o, d := range delta {
+			if d == 0 {
+				continue
+			}
+			g.Biases[l][o] += d
+			row := w[o*in : (o+1)*in]
+			grow := g.Weights[l][o*in : (o+1)*in]
+			for i, v := range prev {
+				grow[i] += d * v
+				if back != nil {
+					back[i] += d * row[i]
+				}
+			}
+		}
+		if l > 0 {
+			// ReLU derivative of the hidden layer feeding this one
+			for i, v := range prev {
+				if v <= 0 {
+					back[i] = 0
+				}
+			}
+		}
+		delta = back
+	}
+}
+
+// Adam updates an MLP from accumulated gradients.
+type Adam struct {
+	LearningRate float64
+	Beta1, Beta2 float64
+	Epsilon      float64
+	// MaxNorm clips the global gradient norm when positive.
+	MaxNorm float64
+
+	step int
+	m, v *Grads
+}
+
+// NewAdam returns Adam with the usual defaults.
+func NewAdam(learningRate float64) *Adam {
+	return &Adam{LearningRate: learningRate, Beta1: 0.9, Beta2: 0.999, Epsilon: 1e-8, MaxNorm: 5}
+}
+
+// Step applies g, averaged over n samples, to net.
+func (a *Adam) Step(net *MLP, g *Grads, n int) {
+	if a.m == nil {
+		a.m, a.v = net.NewGrads(), net.NewGrads()
+	}
+	a.step++
+	scale := 1 / float64(n)
+	if a.MaxNorm > 0 {
+		norm := 0.0
+		for l := range g.Weights {
+			for _, x := range g.Weights[l] {
+				norm += x * x
+			}
+			for _, x := range g.Biases[l] {
+				norm += x * x
+			}
+		}
+		if norm = math.Sqrt(norm) * scale; norm > a.MaxNorm {
+			scale *= a.MaxNorm / norm
+		}
+	}
+	c1 := 1 - math.Pow(a.Beta1, float64(a.step))
+	c2 := 1 - math.Pow(a.Beta2, float64(a.step))
+	update := func(p, grad, m, v []float64) {
+		for i := range p {
+			gi := grad[i] * scale
+			m[i] = a.Beta1*m[i] + (1-a.Beta1)*gi
+			v[i] = a.Beta2*v[i] + (1-a.Beta2)*gi*gi
+			p[i] -= a.LearningRate * (m[i] / c1) / (math.Sqrt(v[i]/c2) + a.Epsilon)
+		}
+	}
+	for l := range net.Weights {
+		update(net.Weights[l], g.Weights[l], a.m.Weights[l], a.v.Weights[l])
+		update(net.Biases[l], g.Biases[l], a.m.Biases[l], a.v.Biases[l])
+	}
+}
+
+// Save writes the network as JSON.
+func (m *MLP) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+// Load reads a network written by Save and checks its shape.
+func Load(r io.Reader) (*MLP, error) {
+	var m MLP
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	if len(m.Sizes) < 2 || len(m.Weights) != len(m.Sizes)-1 || len(m.Biases) != len(m.Weights) {
+		return nil, fmt.Errorf("nn: malformed network with sizes %v", m.Sizes)
+	}
+	for l := range m.Weights {
+		if len(m.Weights[l]) != m.Sizes[l]*m.Sizes[l+1] || len(m.Biases[l]) != m.Sizes[l+1] {
+			return nil, fmt.Errorf("nn: layer %d does not match sizes %v", l, m.Sizes)
+		}
+	}
+	return &m, nil
+}
@@ -0,0 +1,73 @@
+package nn
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBackwardMatchesNumericalGradient(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := New(rng, 4, 5, 3)
+	x := []float64{0.5, -1, 2, 0.1}
+	// loss = sum of outputs weighted by c
+	c := []float64{1, -2, 0.5}
+	loss := func() float64 {
+		out := m.Forward(x).Output()
+		s := 0.0
+		for i, v := range out {
+			s += c[i] * v
+		}
+		return s
+	}
+	g := m.NewGrads()
+	m.Backward(m.Forward(x), c, g)
+	const eps = 1e-6
+	for l := range m.Weights {
+		for i := range m.Weights[l] {
+			orig := m.Weights[l][i]
+			m.Weights[l][i] = orig + eps
+			up := loss()
+			m.Weights[l][i] = orig - eps
+			down := loss()
+			m.Weights[l][i] = orig
+			if num := (up - down) / (2 * eps); math.Abs(num-g.Weights[l][i]) > 1e-4 {
+				t.Fatalf("layer %d weight %d: backprop %g, numerical %g", l, i, g.Weights[l][i], num)
+			}
+		}
+	}
+}
+
+func TestAdamFitsXOR(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	m := New(rng, 2, 8, 1)
+	opt := NewAdam(0.05)
+	data := [][3]float64{{0, 0, 0}, {0, 1, 1}, {1, 0, 1}, {1, 1, 0}}
+	g := m.NewGrads()
+	for epoch := 0; epoch < 500; epoch++ {
+		g.Zero()
+		for _, d := range data {
+			tr := m.Forward([]float64{d[0], d[1]})
+			m.Backward(tr, []float64{2 * (tr.Output()[0] - d[2])}, g)
+		}
+		opt.Step(m, g, len(data))
+	}
+	for _, d := range data {
+		if out := m.Forward([]float64{d[0], d[1]}).Output()[0]; math.Abs(out-d[2]) > 0.1 {
+			t.Fatalf("xor(%v, %v) = %.3f, want %v", d[0], d[1], out, d[2])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := m.Forward([]float64{1, 0}).Output()[0], loaded.Forward([]float64{1, 0}).Output()[0]; a != b {
+		t.Fatalf("loaded network gives %v, want %v", b, a)
+	}
+}
@@ -0,0 +1,133 @@
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// BidTable is an auction strategy solved offline by counterfactual regret
+// minimisation (see internal/cfr). Hands are bucketed by EstimateHandValue;
+// the engine only lets a bidder pass or raise by MinRaise, so each entry is
+// the probability of raising.
+type BidTable struct {
+	Iterations int
+	Deals      int
+	// MaxBid is the highest bid the abstraction allows.
+	MaxBid int
+	// Raise maps BidKey to the average-strategy probability of raising.
+	Raise map[string]float64
+}
+
+// BidBucket buckets a hand by its estimated value, 60 to 300 in steps of 10.
+func BidBucket(hand []engine.Card) int {
+	return min(max(EstimateHandValue(hand), 60), 300)
+}
+
+// BidKey identifies an auction decision: the bidder's role, hand bucket and
+// the bid to beat.
+func BidKey(dealer bool, bucket, high int) string {
+	role := "o"
+	if dealer {
+		role = "d"
+	}
+	return fmt.Sprintf("%s/%d/%d", role, bucket, high)
+}
+
+// RaiseProbability looks up the decision for v; ok is false when the table
+// has no entry for it.
+func (t *BidTable) RaiseProbability(v engine.PlayerView) (p float64, ok bool) {
+	p, ok = t.Raise[BidKey(v.Dealer == v.Seat, BidBucket(v.Hand), v.HighestBid)]
+	return p, ok
+}
+
+// ReadBidTable decodes a table written by WriteTo.
+func ReadBidTable(r io.Reader) (*BidTable, error) {
+	var t BidTable
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, err
+	}
+	if t.Raise == nil {
+		return nil, fmt.Errorf("bid table has no strategy")
+	}
+	return &t, nil
+}
+
+// WriteTo writes the table as JSON.
+func (t *BidTable) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.MarshalIndent(t, "", " ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+var bidTables sync.Map // path -> *BidTable
+
+// LoadBidTable reads a bid table, caching it by path.
+func LoadBidTable(path string) (*BidTable, error) {
+	if t, ok := bidTables.Load(path); ok {
+		return t.(*BidTable), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	t, err := ReadBidTable(f)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	v, _ := bidTables.LoadOrStore(path, t)
+	return v.(*BidTable), nil
+}
+
+// CFRBot bids from a BidTable and plays like HeuristicBot. Decisions outside
+// the table fall back to HeuristicBot's bid. There is no contract decision
+// after the musik: the engine fixes the contract when the auction ends.
+type CFRBot struct {
+	HeuristicBot
+	Table *BidTable
+	// Rand samples mixed strategies when Sample is set; otherwise the bot
+	// raises whenever the table favours it. A source seeded with 1 is used
+	// when nil.
+	Rand   *rand.Rand
+	Sample bool
+}
+
+func (b *CFRBot) Name() string {
+	if b.BotName == "" {
+		return "CFRBot"
+	}
+	return b.BotName
+}
+
+func (b *CFRBot) MakeBidDecision(view engine.PlayerView) (int, error) {
+	if len(view.LegalBids) == 0 {
+		return 0, fmt.Errorf("no legal bids")
+	}
+	if len(view.LegalBids) < 2 {
+		return view.LegalBids[0], nil
+	}
+	p, ok := b.Table.RaiseProbability(view)
+	if !ok || view.LegalBids[1] > b.Table.MaxBid {
+		return b.HeuristicBot.MakeBidDecision(view)
+	}
+	raise := p > 0.5
+	if b.Sample {
+		if b.Rand == nil {
+			b.Rand = rand.New(rand.NewSource(1))
+		}
+		raise = b.Rand.Float64() < p
+	}
+	if raise {
+		return view.LegalBids[1], nil
+	}
+	return 0, nil
+}
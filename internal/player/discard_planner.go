@@ -0,0 +1,162 @@
+package player
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// DiscardOption is a pair of cards the declarer could discard.
+type DiscardOption struct {
+	Cards []engine.Card
+	// Static is the heuristic value of the hand the discard leaves: kept
+	// marriages, sure winners, voids to trump into and the expected share
+	// of the table cards.
+	Static float64
+	// Score ranks the options: the mean score swing over the opponent in
+	// rollouts, or Static when the planner does not roll out.
+	Score float64
+	// MakeRate is the fraction of rollouts in which the contract was made;
+	// zero without rollouts.
+	MakeRate float64
+}
+
+// DiscardPlanner chooses the declarer's discards by scoring every pair of
+// cards in hand, 66 with the usual 12. Any bot can use it from
+// ChooseDiscardCards.
+//
+// The table cards (the unchosen musik plus the discards) go to whoever wins
+// the last trick, so discarding points is only partly a gift: the planner
+// credits discarded points by the declarer's rough chance of taking the last
+// trick, against what the cards would have won if kept.
+type DiscardPlanner struct {
+	// Rollouts plays every option out this many times with HeuristicBot on
+	// both sides, against opponent hands and musiks sampled from what the
+	// declarer has not seen. All options share the same samples. Zero scores
+	// options statically.
+	Rollouts int
+	// Rand samples the rollouts; a source seeded with 1 is used when nil.
+	Rand *rand.Rand
+}
+
+// Choose returns the best discard for view.
+func (p *DiscardPlanner) Choose(view engine.PlayerView) []engine.Card {
+	return p.Plan(view)[0].Cards
+}
+
+// Plan scores every discard for view, the declarer's talon-exchange view
+// after the musik was taken, and returns them best first.
+func (p *DiscardPlanner) Plan(view engine.PlayerView) []DiscardOption {
+	hand := view.Hand
+	var options []DiscardOption
+	for i := range hand {
+		for j := i + 1; j < len(hand); j++ {
+			cards := []engine.Card{hand[i], hand[j]}
+			s := staticDiscardValue(without(hand, cards), cards)
+			options = append(options, DiscardOption{Cards: cards, Static: s, Score: s})
+		}
+	}
+	if p.Rollouts > 0 {
+		p.rollOut(view, options)
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].Score != options[j].Score {
+			return options[i].Score > options[j].Score
+		}
+		return keepValue(hand, options[i].Cards[0])+keepValue(hand, options[i].Cards[1]) <
+			keepValue(hand, options[j].Cards[0])+keepValue(hand, options[j].Cards[1])
+	})
+	return options
+}
+
+// rollOut replaces each option's Score with its mean rollout result.
+func (p *DiscardPlanner) rollOut(view engine.PlayerView, options []DiscardOption) {
+	if p.Rand == nil {
+		p.Rand = rand.New(rand.NewSource(1))
+	}
+	me, opp := view.Seat, view.Others()[0]
+	bid := view.HighestBid
+	for i := range options {
+		options[i].Score = 0
+	}
+	for range p.Rollouts {
+		root := Determinize(view, p.Rand)
+		for i := range options {
+			g := root.Clone()
+			if g.Discard(me, options[i].Cards) != nil {
+				continue
+			}
+			for g.Phase != engine.PhaseHandEnd {
+				if g.Apply(heuristicDecision(rolloutView(g), Normal)) != nil {
+					break
+				}
+			}
+			swing := g.Scores.Cumulative[me] - view.Cumulative[me] - (g.Scores.Cumulative[opp] - view.Cumulative[opp])
+			options[i].Score += float64(swing)
+			if g.Scores.DealPoints[me] >= bid {
+				options[i].MakeRate++
+			}
+		}
+	}
+	for i := range options {
+		options[i].Score /= float64(p.Rollouts)
+		options[i].MakeRate /= float64(p.Rollouts)
+	}
+}
+
+// staticDiscardValue values the hand keep left after discarding discards.
+func staticDiscardValue(keep, discards []engine.Card) float64 {
+	value, winners := 0, 0
+	trump := false
+	for _, s := range engine.Suits {
+		inSuit := cardsOfSuit(keep, s)
+		if hasMarriage(keep, s) {
+			value += engine.MarriageValue(s)
+			trump = true
+		}
+		top := topSequence(inSuit)
+		winners += len(top)
+		for _, c := range top {
+			value += engine.PointsFor(c.Rank) + 6
+		}
+		if len(top) > 0 && len(inSuit) >= 4 {
+			value += 5 * (len(inSuit) - len(top))
+		}
+	}
+	if trump {
+		// a void lets the declarer trump the opponent's leads once a
+		// marriage is announced
+		for _, s := range engine.Suits {
+			if len(cardsOfSuit(keep, s)) == 0 {
+				value += 10
+			}
+		}
+	}
+	table := 0
+	for _, c := range discards {
+		table += engine.PointsFor(c.Rank)
+	}
+	return float64(value) + lastTrickChance(winners, trump)*float64(table)
+}
+
+// lastTrickChance roughly estimates how likely the declarer is to win the
+// last trick from the sure winners and trump prospects kept.
+func lastTrickChance(winners int, trump bool) float64 {
+	p := 0.4 + 0.05*float64(winners)
+	if trump {
+		p += 0.1
+	}
+	return min(p, 0.9)
+}
+
+// without returns hand minus cards.
+func without(hand, cards []engine.Card) []engine.Card {
+	out := make([]engine.Card, 0, len(hand))
+	for _, c := range hand {
+		if c != cards[0] && c != cards[1] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package player_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+func parseCards(t *testing.T, s string) []engine.Card {
+	t.Helper()
+	cards, err := engine.ParseCards(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cards
+}
+
+// discardView returns P2's view after declaring and taking 9H JH from the
+// musik: a heart marriage, two short diamonds and nothing else to protect.
+func discardView(t *testing.T) engine.PlayerView {
+	t.Helper()
+	return discardGame(t).View("P2")
+}
+
+func discardGame(t *testing.T) *engine.GameState {
+	t.Helper()
+	deck := parseCards(t, "AH 10H QD KD 10D AD QC KC 10C KS "+
+		"KH QH AS 10S AC 9D JD 9C JC 9S "+
+		"9H JH JS QS")
+	g := engine.NewGame(engine.GameParams{}, "P1", []engine.PlayerID{"P1", "P2"}, nil)
+	if err := match.DealDeck(g, deck); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.PlaceBid("P2", 110); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.PlaceBid("P1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ChooseMusik("P2", 0); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestDiscardPlannerKeepsMarriage(t *testing.T) {
+	view := discardView(t)
+	for _, rollouts := range []int{0, 8} {
+		p := player.DiscardPlanner{Rollouts: rollouts, Rand: rand.New(rand.NewSource(1))}
+		plan := p.Plan(view)
+		if len(plan) != 66 {
+			t.Fatalf("%d options, want 66", len(plan))
+		}
+		for i := 1; i < len(plan); i++ {
+			if plan[i].Score > plan[i-1].Score {
+				t.Fatalf("options not sorted: %v before %v", plan[i-1], plan[i])
+			}
+		}
+		best := plan[0].Cards
+		for _, c := range parseCards(t, "KH QH") {
+			if slices.Contains(best, c) {
+				t.Errorf("rollouts %d: discards %v, breaking the marriage", rollouts, best)
+			}
+		}
+	}
+	if got, want := (&player.DiscardPlanner{}).Choose(view), parseCards(t, "9D JD"); !slices.Equal(got, want) {
+		t.Errorf("static plan discards %v, want the diamond void %v", got, want)
+	}
+}
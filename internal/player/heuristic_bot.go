@@ -0,0 +1,316 @@
+package player
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player/tracker"
+)
+
+// HeuristicBot bids from a hand valuation and plays by rules of thumb:
+// announce marriages early, cash sure winners, hold trumps and only spend
+// high cards on tricks worth taking. It discards with a DiscardPlanner,
+// rolling out every option at Hard. At Easy it does not count cards, only
+// trusts aces to win and sheds its least valuable cards.
+type HeuristicBot struct {
+	NopHooks
+	BotName    string
+	Difficulty Difficulty
+	// Rand samples the discard rollouts at Hard; a source seeded with 1 is
+	// used when nil.
+	Rand *rand.Rand
+}
+
+func (b *HeuristicBot) Name() string {
+	if b.BotName == "" {
+		return "HeuristicBot"
+	}
+	return b.BotName
+}
+
+func (b *HeuristicBot) MakeBidDecision(view engine.PlayerView) (int, error) {
+	return heuristicDecision(view, b.Difficulty).Value, nil
+}
+
+// ChooseMusik takes the first musik: both lie face down, so their expected
+// values are identical.
+func (b *HeuristicBot) ChooseMusik(view engine.PlayerView) (int, error) {
+	return heuristicDecision(view, b.Difficulty).Index, nil
+}
+
+func (b *HeuristicBot) ChooseDiscardCards(view engine.PlayerView) ([]engine.Card, error) {
+	if b.Difficulty == Hard {
+		if b.Rand == nil {
+			b.Rand = rand.New(rand.NewSource(1))
+		}
+		p := DiscardPlanner{Rollouts: 16, Rand: b.Rand}
+		return p.Choose(view), nil
+	}
+	return heuristicDecision(view, b.Difficulty).Cards, nil
+}
+
+func (b *HeuristicBot) PlayCard(view engine.PlayerView) (engine.Card, bool, error) {
+	a := heuristicDecision(view, b.Difficulty)
+	return a.Card, a.Marriage, nil
+}
+
+// NewHeuristicBot returns a HeuristicBot. Only its Hard discards sample, so
+// unlike the other constructors it takes no seed.
+func NewHeuristicBot() Player {
+	return &HeuristicBot{}
+}
+
+// heuristicBid bids the highest legal value the hand is estimated to make.
+func heuristicBid(hand []engine.Card, legalBids []int) int {
+	value := EstimateHandValue(hand)
+	best := 0
+	for _, bid := range legalBids {
+		if bid <= value && bid > best {
+			best = bid
+		}
+	}
+	return best
+}
+
+// heuristicDiscards returns the n cards with the lowest keep value.
+func heuristicDiscards(hand []engine.Card, n int) []engine.Card {
+	sorted := append([]engine.Card{}, hand...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return keepValue(hand, sorted[i]) < keepValue(hand, sorted[j])
+	})
+	return sorted[:n]
+}
+
+// heuristicDecision returns the HeuristicBot action for the viewing player.
+func heuristicDecision(v engine.PlayerView, d Difficulty) engine.Action {
+	switch v.Phase {
+	case engine.PhaseAuction:
+		return engine.Action{Type: engine.ActionBid, Player: v.Seat, Value: heuristicBid(v.Hand, v.LegalBids)}
+	case engine.PhaseTalonExchange:
+		if v.MusikCount > 0 {
+			return engine.Action{Type: engine.ActionChooseMusik, Player: v.Seat}
+		}
+		if d == Easy {
+			return engine.Action{Type: engine.ActionDiscard, Player: v.Seat, Cards: heuristicDiscards(v.Hand, 2)}
+		}
+		var p DiscardPlanner
+		return engine.Action{Type: engine.ActionDiscard, Player: v.Seat, Cards: p.Choose(v)}
+	}
+	if len(v.CurrentTrick.Plays) == 0 {
+		var tr *tracker.Tracker
+		if d != Easy {
+			tr = tracker.FromView(v)
+		}
+		card, marriage := chooseLead(v.Hand, tr, v.Others(), v.Trump, v.MarriageAllowed())
+		return engine.Action{Type: engine.ActionPlayCard, Player: v.Seat, Card: card, Marriage: marriage}
+	}
+	card := chooseFollow(v.LegalPlays, v.CurrentTrick, v.Trump)
+	return engine.Action{Type: engine.ActionPlayCard, Player: v.Seat, Card: card}
+}
+
+// EstimateHandValue estimates the deal points a hand could make as declarer:
+// marriage values plus the points of sure winners and the cards they capture.
+// The result is rounded down to a multiple of 10.
+func EstimateHandValue(hand []engine.Card) int {
+	value := 10 // expected help from the musik
+	for _, s := range engine.Suits {
+		if hasMarriage(hand, s) {
+			value += engine.MarriageValue(s)
+		}
+		inSuit := cardsOfSuit(hand, s)
+		winners := topSequence(inSuit)
+		for _, c := range winners {
+			value += engine.PointsFor(c.Rank) + 6
+		}
+		if len(winners) > 0 && len(inSuit) >= 4 {
+			value += 5 * (len(inSuit) - len(winners))
+		}
+	}
+	return value / 10 * 10
+}
+
+// topSequence returns the cards of a single suit that are unbeatable from the
+// top down: the ace, then the ten if the ace is held, and so on.
+func topSequence(inSuit []engine.Card) []engine.Card {
+	var out []engine.Card
+	for i := len(engine.RankOrder) - 1; i >= 0; i-- {
+		c, ok := findRank(inSuit, engine.RankOrder[i])
+		if !ok {
+			break
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func findRank(cards []engine.Card, r engine.Rank) (engine.Card, bool) {
+	for _, c := range cards {
+		if c.Rank == r {
+			return c, true
+		}
+	}
+	return engine.Card{}, false
+}
+
+func cardsOfSuit(cards []engine.Card, s engine.Suit) []engine.Card {
+	var out []engine.Card
+	for _, c := range cards {
+		if c.Suit == s {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func hasMarriage(hand []engine.Card, s engine.Suit) bool {
+	_, k := findRank(cardsOfSuit(hand, s), engine.King)
+	_, q := findRank(cardsOfSuit(hand, s), engine.Queen)
+	return k && q
+}
+
+func rankIndex(r engine.Rank) int {
+	for i, v := range engine.RankOrder {
+		if v == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// beats reports whether a wins over b given the led suit and trump.
+func beats(a, b engine.Card, led engine.Suit, trump *engine.Suit) bool {
+	if trump != nil && a.Suit != b.Suit {
+		if a.Suit == *trump {
+			return true
+		}
+		if b.Suit == *trump {
+			return false
+		}
+	}
+	if a.Suit == b.Suit {
+		return rankIndex(a.Rank) > rankIndex(b.Rank)
+	}
+	return a.Suit == led
+}
+
+// keepValue scores how much the declarer wants to keep a card; the lowest
+// scoring cards are discarded.
+func keepValue(hand []engine.Card, c engine.Card) int {
+	v := engine.PointsFor(c.Rank) + rankIndex(c.Rank)
+	if (c.Rank == engine.King || c.Rank == engine.Queen) && hasMarriage(hand, c.Suit) {
+		v += engine.MarriageValue(c.Suit)
+	}
+	for _, w := range topSequence(cardsOfSuit(hand, c.Suit)) {
+		if w == c {
+			v += 20
+		}
+	}
+	// shedding the last cards of a short suit makes a void to trump into
+	if n := len(cardsOfSuit(hand, c.Suit)); n <= 2 {
+		v -= 3 - n
+	}
+	return v
+}
+
+// isSafeWinner reports whether c is the highest card left in its suit and
+// no opponent can trump it. Without a tracker only aces count.
+func isSafeWinner(c engine.Card, tr *tracker.Tracker, opponents []engine.PlayerID) bool {
+	if tr == nil {
+		return c.Rank == engine.Ace
+	}
+	if !tr.IsHighestRemaining(c) {
+		return false
+	}
+	for _, opp := range opponents {
+		if tr.IsVoid(opp, c.Suit) && tr.CanHoldTrump(opp) {
+			return false
+		}
+	}
+	return true
+}
+
+func chooseLead(hand []engine.Card, tr *tracker.Tracker, opponents []engine.PlayerID, trump *engine.Suit, marriages bool) (engine.Card, bool) {
+	// announce the most valuable marriage, leading the cheaper queen
+	for i := len(engine.Suits) - 1; i >= 0 && marriages; i-- {
+		s := engine.Suits[i]
+		if hasMarriage(hand, s) {
+			return engine.Card{Suit: s, Rank: engine.Queen}, true
+		}
+	}
+	isTrump := func(c engine.Card) bool { return trump != nil && c.Suit == *trump }
+	// cash the most valuable sure winner outside trumps
+	var best *engine.Card
+	for i, c := range hand {
+		if isTrump(c) || !isSafeWinner(c, tr, opponents) {
+			continue
+		}
+		if best == nil || engine.PointsFor(c.Rank) > engine.PointsFor(best.Rank) {
+			best = &hand[i]
+		}
+	}
+	if best != nil {
+		return *best, false
+	}
+	// otherwise lead the cheapest card, preferring non-trumps
+	cheapest := hand[0]
+	for _, c := range hand[1:] {
+		if isTrump(cheapest) != isTrump(c) {
+			if isTrump(cheapest) {
+				cheapest = c
+			}
+			continue
+		}
+		if rankIndex(c.Rank) < rankIndex(cheapest.Rank) {
+			cheapest = c
+		}
+	}
+	return cheapest, false
+}
+
+func chooseFollow(legal []engine.Card, trick engine.Trick, trump *engine.Suit) engine.Card {
+	led := trick.Plays[0].Card.Suit
+	winning := trick.Plays[0].Card
+	for _, p := range trick.Plays[1:] {
+		if beats(p.Card, winning, led, trump) {
+			winning = p.Card
+		}
+	}
+	trickPoints := 0
+	for _, p := range trick.Plays {
+		trickPoints += engine.PointsFor(p.Card.Rank)
+	}
+	isTrump := func(c engine.Card) bool { return trump != nil && c.Suit == *trump }
+
+	// cheapest card that takes the trick; trumping in only pays for valuable tricks
+	var win *engine.Card
+	for i, c := range legal {
+		if !beats(c, winning, led, trump) {
+			continue
+		}
+		if isTrump(c) && c.Suit != led && trickPoints < 10 {
+			continue
+		}
+		if win == nil || engine.PointsFor(c.Rank) < engine.PointsFor(win.Rank) ||
+			(engine.PointsFor(c.Rank) == engine.PointsFor(win.Rank) && rankIndex(c.Rank) < rankIndex(win.Rank)) {
+			win = &legal[i]
+		}
+	}
+	if win != nil {
+		return *win
+	}
+	// cannot win: dump the cheapest non-trump card
+	dump := legal[0]
+	for _, c := range legal[1:] {
+		if isTrump(dump) != isTrump(c) {
+			if isTrump(dump) {
+				dump = c
+			}
+			continue
+		}
+		if engine.PointsFor(c.Rank) < engine.PointsFor(dump.Rank) {
+			dump = c
+		}
+	}
+	return dump
+}
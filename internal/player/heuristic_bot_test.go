@@ -0,0 +1,99 @@
+package player_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+var heuristicPlayers = engine.GameParams{Players: []engine.PlayerID{"P1", "P2"}, HandCards: 10, MusiksCount: 2, MusikSize: 2}
+
+func TestHeuristicBidsEstimatedValue(t *testing.T) {
+	for _, tc := range []struct {
+		hand  string
+		legal []int
+		want  int
+	}{
+		// two marriages and a string of aces and tens
+		{"AH 10H KH QH AS 10S KS QS AD 10D", []int{0, 110}, 110},
+		{"AH 10H KH QH AS 10S KS QS AD 10D", []int{0, 300}, 0},
+		{"9H JH 9S JS 9D JD 9C JC QD KC", []int{0, 110}, 0},
+	} {
+		hand := parseCards(t, tc.hand)
+		view := engine.PlayerView{Seat: "P2", Params: heuristicPlayers, Phase: engine.PhaseAuction, Hand: hand, LegalBids: tc.legal}
+		bid, err := player.NewHeuristicBot().MakeBidDecision(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bid != tc.want {
+			t.Errorf("%s valued %d: bid %d from %v, want %d", tc.hand, player.EstimateHandValue(hand), bid, tc.legal, tc.want)
+		}
+	}
+}
+
+func TestHeuristicLeadsMarriage(t *testing.T) {
+	view := engine.PlayerView{Seat: "P1", Params: heuristicPlayers, Phase: engine.PhasePlay,
+		Hand: parseCards(t, "KS QS KH QH 9C JC 9D JD")}
+	view.LegalPlays = view.Hand
+	for _, d := range []player.Difficulty{player.Easy, player.Normal} {
+		card, marriage, err := (&player.HeuristicBot{Difficulty: d}).PlayCard(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := parseCards(t, "QH")[0]; card != want || !marriage {
+			t.Errorf("%v: led %v, marriage %v; want the heart marriage with %v", d, card, marriage, want)
+		}
+	}
+}
+
+func TestHeuristicFollowsAndDumps(t *testing.T) {
+	hearts := engine.Hearts
+	for _, tc := range []struct {
+		name  string
+		led   string
+		legal string
+		want  string
+	}{
+		{"takes with the cheapest winner", "QH", "AH 10H", "10H"},
+		{"dumps when it cannot win", "AS", "KS 9S", "9S"},
+		{"trumps a valuable trick", "AD", "KC 9H JC", "9H"},
+		{"keeps trumps for cheap tricks", "9D", "KC 9H JC", "JC"},
+	} {
+		led := parseCards(t, tc.led)[0]
+		legal := parseCards(t, tc.legal)
+		view := engine.PlayerView{Seat: "P1", Params: heuristicPlayers, Phase: engine.PhasePlay, Trump: &hearts,
+			Hand: legal, LegalPlays: legal,
+			CurrentTrick: engine.Trick{Leader: "P2", LedSuit: &led.Suit, Plays: []engine.Play{{Player: "P2", Card: led}}}}
+		card, _, err := player.NewHeuristicBot().PlayCard(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := parseCards(t, tc.want)[0]; card != want {
+			t.Errorf("%s: played %v on %v, want %v", tc.name, card, led, want)
+		}
+	}
+}
+
+func TestHeuristicHardDiscardsAreSeeded(t *testing.T) {
+	view := discardView(t)
+	choose := func(seed int64) []engine.Card {
+		b, err := player.NewFromSpec("heuristic:hard", seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cards, err := b.ChooseDiscardCards(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cards
+	}
+	cards := choose(3)
+	if len(cards) != 2 || !slices.Contains(view.Hand, cards[0]) || !slices.Contains(view.Hand, cards[1]) {
+		t.Fatalf("discards %v from %v", cards, view.Hand)
+	}
+	if again := choose(3); !slices.Equal(again, cards) {
+		t.Errorf("same seed discarded %v then %v", cards, again)
+	}
+}
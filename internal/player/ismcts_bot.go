@@ -0,0 +1,329 @@
+package player
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player/tracker"
+)
+
+// ISMCTSBot decides with single-observer information-set Monte Carlo tree
+// search. Every iteration samples the hidden cards with Determinize, then
+// descends one tree shared by all samples. Playouts follow HeuristicBot.
+type ISMCTSBot struct {
+	NopHooks
+	BotName string
+	// Iterations caps the number of iterations per decision.
+	Iterations int
+	// TimeBudget caps the wall-clock time per decision.
+	TimeBudget time.Duration
+	// Exploration is the UCB exploration constant.
+	Exploration float64
+	// Rand drives sampling and expansion; a source seeded with 1 is used
+	// when nil. Searches bounded by TimeBudget are not reproducible.
+	Rand *rand.Rand
+	// Done, if set, ends searches early once closed, e.g. with a
+	// context's Done channel; the best action found so far is returned.
+	Done <-chan struct{}
+}
+
+func (b *ISMCTSBot) Name() string {
+	if b.BotName == "" {
+		return "ISMCTSBot"
+	}
+	return b.BotName
+}
+
+func (b *ISMCTSBot) rng() *rand.Rand {
+	if b.Rand == nil {
+		b.Rand = rand.New(rand.NewSource(1))
+	}
+	return b.Rand
+}
+
+func (b *ISMCTSBot) MakeBidDecision(view engine.PlayerView) (int, error) {
+	if len(view.LegalBids) == 0 {
+		return 0, fmt.Errorf("no legal bids")
+	}
+	if len(view.LegalBids) < 2 {
+		return view.LegalBids[0], nil
+	}
+	return b.search(view).Value, nil
+}
+
+func (b *ISMCTSBot) ChooseMusik(view engine.PlayerView) (int, error) {
+	return b.search(view).Index, nil
+}
+
+func (b *ISMCTSBot) ChooseDiscardCards(view engine.PlayerView) ([]engine.Card, error) {
+	return b.search(view).Cards, nil
+}
+
+func (b *ISMCTSBot) PlayCard(view engine.PlayerView) (engine.Card, bool, error) {
+	if len(view.LegalPlays) == 1 && !view.Leading() {
+		return view.LegalPlays[0], false, nil
+	}
+	a := b.search(view)
+	return a.Card, a.Marriage, nil
+}
+
+// NewISMCTSBot returns an ISMCTSBot searching 1000 iterations per decision
+// with random choices drawn from seed.
+func NewISMCTSBot(seed int64) Player {
+	return &ISMCTSBot{Iterations: 1000, Exploration: 0.7, Rand: rand.New(rand.NewSource(seed))}
+}
+
+// searchNode is a node of the information-set tree. Children are keyed by
+// action; avail counts how often a child was legal when its parent was visited.
+type searchNode struct {
+	key      string
+	action   engine.Action
+	children []*searchNode
+	visits   int
+	avail    int
+	reward   float64 // summed from the point of view of action.Player
+}
+
+func (n *searchNode) child(key string) *searchNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+func actionKey(a engine.Action) string {
+	cards := append([]engine.Card{}, a.Cards...)
+	sort.Slice(cards, func(i, j int) bool { return cards[i].Code() < cards[j].Code() })
+	return fmt.Sprintf("%s %s %d %d %s %s %t", a.Type, a.Player, a.Value, a.Index, engine.FormatCards(cards), a.Card.Code(), a.Marriage)
+}
+
+// search runs ISMCTS from determinizations of view and returns the most
+// visited root action. At least one iteration runs however small the budget;
+// should none expand the root, the heuristic decides.
+func (b *ISMCTSBot) search(view engine.PlayerView) engine.Action {
+	iterations := b.Iterations
+	if iterations <= 0 && b.TimeBudget <= 0 {
+		iterations = 1000
+	}
+	deadline := time.Now().Add(b.TimeBudget)
+	root := &searchNode{}
+	for i := 0; i == 0 || (iterations <= 0 || i < iterations) && (b.TimeBudget <= 0 || time.Now().Before(deadline)) && !b.stopped(); i++ {
+		b.iterate(root, Determinize(view, b.rng()), view.Cumulative)
+	}
+	if len(root.children) == 0 {
+		return heuristicDecision(view, Normal)
+	}
+	best := root.children[0]
+	for _, c := range root.children[1:] {
+		if c.visits > best.visits {
+			best = c
+		}
+	}
+	return best.action
+}
+
+// stopped reports whether Done is closed.
+func (b *ISMCTSBot) stopped() bool {
+	select {
+	case <-b.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *ISMCTSBot) iterate(root *searchNode, g *engine.GameState, start map[engine.PlayerID]int) {
+	c := b.Exploration
+	if c == 0 {
+		c = 0.7
+	}
+	path := []*searchNode{}
+	n := root
+	for g.Phase != engine.PhaseHandEnd {
+		var untried []engine.Action
+		var compatible []*searchNode
+		for _, a := range g.LegalActions() {
+			if child := n.child(actionKey(a)); child != nil {
+				compatible = append(compatible, child)
+			} else {
+				untried = append(untried, a)
+			}
+		}
+		for _, child := range compatible {
+			child.avail++
+		}
+		if len(untried) > 0 {
+			a := untried[b.rng().Intn(len(untried))]
+			child := &searchNode{key: actionKey(a), action: a, avail: 1}
+			n.children = append(n.children, child)
+			if g.Apply(a) != nil {
+				return
+			}
+			path = append(path, child)
+			break
+		}
+		best, bestScore := compatible[0], math.Inf(-1)
+		for _, child := range compatible {
+			score := child.reward/float64(child.visits) + c*math.Sqrt(math.Log(float64(child.avail))/float64(child.visits))
+			if score > bestScore {
+				best, bestScore = child, score
+			}
+		}
+		if g.Apply(best.action) != nil {
+			return
+		}
+		path = append(path, best)
+		n = best
+	}
+	for g.Phase != engine.PhaseHandEnd {
+		if g.Apply(heuristicDecision(rolloutView(g), Normal)) != nil {
+			return
+		}
+	}
+	for _, node := range path {
+		node.visits++
+		node.reward += handReward(g, node.action.Player, start)
+	}
+}
+
+// rolloutView is a cheap stand-in for GameState.View holding just what
+// heuristicDecision reads. It shares memory with g and must not be kept.
+func rolloutView(g *engine.GameState) engine.PlayerView {
+	p := g.ActingPlayer()
+	v := engine.PlayerView{
+		Seat:            p,
+		Phase:           g.Phase,
+		Hand:            g.Deal.Hands[p],
+		MusikCount:      len(g.Deal.Musiks),
+		LegalBids:       g.LegalBids(p),
+		Trump:           g.Play.Trump,
+		CurrentTrick:    g.Play.CurrentTrick,
+		CompletedTricks: g.Play.CompletedTricks,
+		Params:          g.Params,
+	}
+	if g.Phase == engine.PhasePlay {
+		v.LegalPlays = g.LegalPlays(p)
+	}
+	return v
+}
+
+// handReward scores a finished hand for p as its cumulative score swing
+// against the opponent since start, scaled to roughly [-1, 1].
+func handReward(g *engine.GameState, p engine.PlayerID, start map[engine.PlayerID]int) float64 {
+	diff := 0
+	for q, v := range g.Scores.Cumulative {
+		if q == p {
+			diff += v - start[q]
+		} else {
+			diff -= v - start[q]
+		}
+	}
+	return float64(diff) / 300
+}
+
+// Determinize samples a full game state consistent with view: the hidden
+// cards are dealt at random to the opponent, the musiks and the table,
+// keeping the opponent out of suits it has shown to be void in. Before play
+// the opponent's hand is also one its bids were legal from, as when the
+// rules cap the bids of a player without a marriage.
+func Determinize(view engine.PlayerView, rng *rand.Rand) *engine.GameState {
+	me, opp := view.Seat, view.Others()[0]
+	tr := tracker.FromView(view)
+	hidden := tr.Unseen()
+	rng.Shuffle(len(hidden), func(i, j int) { hidden[i], hidden[j] = hidden[j], hidden[i] })
+	p := view.Params
+	players := p.Players
+
+	if view.Phase < engine.PhasePlay {
+		var g *engine.GameState
+		for range maxDeterminizeDeals {
+			var ok bool
+			if g, ok = determinizeDeal(view, hidden); ok {
+				break
+			}
+			rng.Shuffle(len(hidden), func(i, j int) { hidden[i], hidden[j] = hidden[j], hidden[i] })
+		}
+		return g
+	}
+
+	oppHand, table := dealRespectingVoids(hidden, view.HandSizes[opp], tr, opp)
+	declarer := *view.Declarer
+	g := engine.NewGame(p, view.Dealer, players, view.Cumulative)
+	g.Phase = view.Phase
+	g.Declarer = &declarer
+	g.Auction = engine.AuctionState{Bids: view.Bids, ActivePlayers: []engine.PlayerID{declarer}, CurrentLeader: declarer, MinRaise: p.MinRaise}
+	g.Deal = engine.DealState{
+		Hands:      map[engine.PlayerID][]engine.Card{me: view.Hand, opp: oppHand},
+		TableCards: append(table, view.OwnDiscards...),
+	}
+	g.Play = engine.PlayState{
+		CurrentTrick:    view.CurrentTrick,
+		CompletedTricks: view.CompletedTricks,
+		Trump:           view.Trump,
+		RemainingCards:  len(view.Hand) + len(oppHand) + len(view.CurrentTrick.Plays),
+	}
+	if n := len(view.CompletedTricks); n > 0 {
+		last := view.CompletedTricks[n-1]
+		g.Play.LastTrickWinner = &last.Plays[last.WinningPlayIndex].Player
+	}
+	g.Scores.DealPoints = view.DealPoints
+	return g.Clone()
+}
+
+// maxDeterminizeDeals bounds the deals Determinize tries before play to
+// find one the bids are legal from.
+const maxDeterminizeDeals = 50
+
+// determinizeDeal deals hidden out around view's hand and replays the
+// auction, reporting whether every bid was legal.
+func determinizeDeal(view engine.PlayerView, hidden []engine.Card) (*engine.GameState, bool) {
+	me, opp := view.Seat, view.Others()[0]
+	p := view.Params
+	g := engine.NewGame(p, view.Dealer, p.Players, view.Cumulative)
+	hands := map[engine.PlayerID][]engine.Card{}
+	var musiks [][]engine.Card
+	chosen := view.Phase == engine.PhaseTalonExchange && view.MusikCount == 0
+	if chosen && *view.Declarer == me {
+		// the chosen musik is already in hand: deal it back out as musik 0
+		hands[me] = append([]engine.Card{}, view.Hand[:p.HandCards]...)
+		musiks = append(musiks, append([]engine.Card{}, view.Hand[p.HandCards:]...))
+	} else {
+		hands[me] = append([]engine.Card{}, view.Hand...)
+	}
+	hands[opp], hidden = append([]engine.Card{}, hidden[:p.HandCards]...), hidden[p.HandCards:]
+	for len(musiks) < p.MusiksCount {
+		musiks, hidden = append(musiks, append([]engine.Card{}, hidden[:p.MusikSize]...)), hidden[p.MusikSize:]
+	}
+	ok := g.SetDealtCards(hands, musiks) == nil
+	for _, bid := range view.Bids[1:] {
+		ok = g.PlaceBid(bid.Player, bid.Value) == nil && ok
+	}
+	if chosen {
+		_ = g.ChooseMusik(*view.Declarer, 0)
+	}
+	return g, ok
+}
+
+// dealRespectingVoids gives the opponent n of cards, avoiding suits it is
+// known to be void in when possible; the rest go to the table.
+func dealRespectingVoids(cards []engine.Card, n int, tr *tracker.Tracker, opp engine.PlayerID) (oppHand, table []engine.Card) {
+	var free []engine.Card
+	for _, c := range cards {
+		if !tr.CanHold(opp, c) {
+			table = append(table, c)
+		} else {
+			free = append(free, c)
+		}
+	}
+	if len(free) < n {
+		// observations are inconsistent with the card count; ignore voids
+		return append([]engine.Card{}, cards[:n]...), append([]engine.Card{}, cards[n:]...)
+	}
+	return free[:n], append(table, free[n:]...)
+}
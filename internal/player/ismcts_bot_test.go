@@ -0,0 +1,105 @@
+package player_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// voids returns the suits p has failed to follow in view's tricks.
+func voids(view engine.PlayerView, p engine.PlayerID) map[engine.Suit]bool {
+	out := map[engine.Suit]bool{}
+	for _, t := range append(append([]engine.Trick{}, view.CompletedTricks...), view.CurrentTrick) {
+		for _, pl := range t.Plays[min(1, len(t.Plays)):] {
+			if led := t.Plays[0].Card.Suit; pl.Player == p && pl.Card.Suit != led {
+				out[led] = true
+			}
+		}
+	}
+	return out
+}
+
+func TestDeterminizeIsConsistent(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	g := engine.NewGame(engine.GameParams{}, "P1", []engine.PlayerID{"P1", "P2"}, nil)
+	if err := match.Deal(g, rng); err != nil {
+		t.Fatal(err)
+	}
+	bot := player.NewHeuristicBot()
+	sawVoid := false
+	for g.Phase != engine.PhaseHandEnd {
+		for _, seat := range g.Params.Players {
+			view := g.View(seat)
+			opp := view.Others()[0]
+			void := voids(view, opp)
+			sawVoid = sawVoid || len(void) > 0
+			for range 20 {
+				d := player.Determinize(view, rng)
+				if err := d.Validate(); err != nil {
+					t.Fatalf("%s in %v: %v", seat, view.Phase, err)
+				}
+				if d.Phase != view.Phase || d.ActingPlayer() != g.ActingPlayer() {
+					t.Fatalf("%s: determinized %v with %s to act, want %v with %s", seat, d.Phase, d.ActingPlayer(), view.Phase, g.ActingPlayer())
+				}
+				dv := d.View(seat)
+				if !slices.Equal(dv.Hand, view.Hand) {
+					t.Fatalf("%s holds %v, determinized %v", seat, view.Hand, dv.Hand)
+				}
+				for p, n := range view.HandSizes {
+					if got := len(d.Deal.Hands[p]); got != n {
+						t.Fatalf("%s: %s holds %d cards, want %d", seat, p, got, n)
+					}
+				}
+				for _, c := range d.Deal.Hands[opp] {
+					if void[c.Suit] {
+						t.Fatalf("%s: %s dealt %v of a suit it is void in", seat, opp, c)
+					}
+				}
+			}
+		}
+		a, err := match.Decide(bot, g.View(g.ActingPlayer()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Step(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !sawVoid {
+		t.Error("the hand revealed no void; pick another deal")
+	}
+}
+
+func TestISMCTSFixedIterations(t *testing.T) {
+	view := leadView(t)
+	search := func(b *player.ISMCTSBot) (engine.Card, bool) {
+		t.Helper()
+		card, marriage, err := b.PlayCard(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Contains(view.LegalPlays, card) {
+			t.Fatalf("illegal play %v", card)
+		}
+		return card, marriage
+	}
+	card, marriage := search(&player.ISMCTSBot{Iterations: 300, Rand: rand.New(rand.NewSource(5))})
+	if again, m := search(&player.ISMCTSBot{Iterations: 300, Rand: rand.New(rand.NewSource(5))}); again != card || m != marriage {
+		t.Errorf("same seed chose %v then %v", card, again)
+	}
+
+	// a budget too small for any iteration still runs one
+	search(&player.ISMCTSBot{TimeBudget: time.Nanosecond})
+	done := make(chan struct{})
+	close(done)
+	search(&player.ISMCTSBot{Iterations: 1 << 30, Done: done})
+
+	if _, err := (&player.ISMCTSBot{}).MakeBidDecision(engine.PlayerView{Phase: engine.PhaseAuction}); err == nil {
+		t.Error("bid without legal bids")
+	}
+}
@@ -0,0 +1,197 @@
+package player
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/nn"
+	"github.com/ZygmuntJakub/1000/internal/player/tracker"
+)
+
+// FeatureSize is the length of the vectors returned by EncodeView.
+const FeatureSize = 123
+
+// PolicySize is the number of outputs of a card-play network: one logit per
+// card followed by the value of the position.
+const PolicySize = 25
+
+// CardIndex numbers the 24 cards from 0 by suit, then rank.
+func CardIndex(c engine.Card) int { return int(c.Suit)*6 + int(c.Rank) }
+
+// IndexCard is the inverse of CardIndex.
+func IndexCard(i int) engine.Card {
+	return engine.Card{Suit: engine.Suit(i / 6), Rank: engine.Rank(i % 6)}
+}
+
+// EncodeView turns a play-phase view into network inputs: card sets for the
+// hand, played cards, the current trick and own discards, then trump, led
+// suit, opponent voids, marriages, roles and normalised scores.
+func EncodeView(v engine.PlayerView) []float64 {
+	x := make([]float64, FeatureSize)
+	set := func(offset int, cards []engine.Card) {
+		for _, c := range cards {
+			x[offset+CardIndex(c)] = 1
+		}
+	}
+	set(0, v.Hand)
+	for _, t := range v.CompletedTricks {
+		for _, p := range t.Plays {
+			x[24+CardIndex(p.Card)] = 1
+		}
+	}
+	for _, p := range v.CurrentTrick.Plays {
+		x[48+CardIndex(p.Card)] = 1
+	}
+	set(72, v.OwnDiscards)
+	if v.Trump != nil {
+		x[96+int(*v.Trump)] = 1
+	}
+	if v.CurrentTrick.LedSuit != nil {
+		x[100+int(*v.CurrentTrick.LedSuit)] = 1
+	}
+	tr := tracker.FromView(v)
+	opp := v.Others()[0]
+	for _, s := range tr.Voids(opp) {
+		x[104+int(s)] = 1
+	}
+	for _, m := range v.Marriages {
+		if m.Player == v.Seat {
+			x[108+int(m.Suit)] = 1
+		} else {
+			x[112+int(m.Suit)] = 1
+		}
+	}
+	if v.Declarer != nil {
+		if *v.Declarer == v.Seat {
+			x[116] = 1
+		} else {
+			x[117] = 1
+		}
+	}
+	if v.Leading() {
+		x[118] = 1
+	}
+	x[119] = float64(v.DealPoints[v.Seat]) / 120
+	x[120] = float64(v.DealPoints[opp]) / 120
+	x[121] = float64(v.HighestBid) / 300
+	x[122] = float64(len(v.Hand)) / float64(v.Params.HandCards+v.Params.MusikSize)
+	return x
+}
+
+// PolicyOutput is a network's judgement of a play-phase view.
+type PolicyOutput struct {
+	// Probs is indexed by CardIndex and is zero for illegal cards.
+	Probs []float64
+	// Value estimates the hand's outcome for the seat in [-1, 1].
+	Value float64
+	Trace *nn.Trace
+}
+
+// EvaluatePolicy runs net on v, masking the policy to v.LegalPlays.
+func EvaluatePolicy(net *nn.MLP, v engine.PlayerView) PolicyOutput {
+	t := net.Forward(EncodeView(v))
+	out := t.Output()
+	probs := make([]float64, 24)
+	max := math.Inf(-1)
+	for _, c := range v.LegalPlays {
+		max = math.Max(max, out[CardIndex(c)])
+	}
+	sum := 0.0
+	for _, c := range v.LegalPlays {
+		i := CardIndex(c)
+		probs[i] = math.Exp(out[i] - max)
+		sum += probs[i]
+	}
+	for i := range probs {
+		probs[i] /= sum
+	}
+	return PolicyOutput{Probs: probs, Value: math.Tanh(out[24]), Trace: t}
+}
+
+// AnnouncesMarriage reports whether leading c from v's hand announces a
+// marriage the rules allow, which learned players always do.
+func AnnouncesMarriage(v engine.PlayerView, c engine.Card) bool {
+	return v.Leading() && v.MarriageAllowed() && (c.Rank == engine.King || c.Rank == engine.Queen) && hasMarriage(v.Hand, c.Suit)
+}
+
+// NeuralBot plays cards with a trained policy network and leaves the
+// auction, musik and discards to HeuristicBot.
+type NeuralBot struct {
+	HeuristicBot
+	Net *nn.MLP
+	// Rand samples from the policy when Explore is set; a source seeded
+	// with 1 is used when nil.
+	Rand    *rand.Rand
+	Explore bool
+}
+
+func (b *NeuralBot) Name() string {
+	if b.BotName == "" {
+		return "NeuralBot"
+	}
+	return b.BotName
+}
+
+func (b *NeuralBot) PlayCard(view engine.PlayerView) (engine.Card, bool, error) {
+	probs := EvaluatePolicy(b.Net, view).Probs
+	best := CardIndex(view.LegalPlays[0])
+	if b.Explore {
+		if b.Rand == nil {
+			b.Rand = rand.New(rand.NewSource(1))
+		}
+		best = SampleIndex(probs, b.Rand)
+	} else {
+		for i, p := range probs {
+			if p > probs[best] {
+				best = i
+			}
+		}
+	}
+	c := IndexCard(best)
+	return c, AnnouncesMarriage(view, c), nil
+}
+
+// SampleIndex draws an index with the given probabilities.
+func SampleIndex(probs []float64, rng *rand.Rand) int {
+	r := rng.Float64()
+	last := 0
+	for i, p := range probs {
+		if p == 0 {
+			continue
+		}
+		if r < p {
+			return i
+		}
+		r -= p
+		last = i
+	}
+	return last
+}
+
+var models sync.Map // path -> *nn.MLP
+
+// LoadModel reads a card-play network, caching it by path. Cached networks
+// are shared and must not be trained.
+func LoadModel(path string) (*nn.MLP, error) {
+	if m, ok := models.Load(path); ok {
+		return m.(*nn.MLP), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	net, err := nn.Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	if net.Sizes[0] != FeatureSize || net.Sizes[len(net.Sizes)-1] != PolicySize {
+		return nil, fmt.Errorf("load %s: network %v does not take %d features to %d outputs", path, net.Sizes, FeatureSize, PolicySize)
+	}
+	m, _ := models.LoadOrStore(path, net)
+	return m.(*nn.MLP), nil
+}
@@ -0,0 +1,144 @@
+package player
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// Personality describes how a PersonalityBot bends the decisions of the bot
+// it wraps to feel more like a human at the table. The zero value changes
+// nothing.
+type Personality struct {
+	// Risk, from -1 to 1, is the appetite for bidding. Positive values keep
+	// raising past a pass while the next bid is within Risk*50 points above
+	// the hand's estimated value; negative values pass instead of raising
+	// once the next bid is above that value lowered by -Risk*50.
+	Risk float64
+	// ErrorRate is the chance of replacing a card or discard with a random
+	// legal one.
+	ErrorRate float64
+	// MissMarriage is the chance of leading a marriage card without
+	// announcing the marriage.
+	MissMarriage float64
+	// Delay is the average thinking time before each decision; the actual
+	// pause varies between half and one and a half times it.
+	Delay time.Duration
+}
+
+// personalities are the named presets accepted in bot specs.
+var personalities = map[string]Personality{
+	"beginner":   {Risk: 0.3, ErrorRate: 0.25, MissMarriage: 0.5},
+	"casual":     {ErrorRate: 0.08, MissMarriage: 0.15},
+	"aggressive": {Risk: 0.6},
+	"cautious":   {Risk: -0.6},
+}
+
+// ParsePersonality returns a named preset: beginner, casual, aggressive or
+// cautious.
+func ParsePersonality(name string) (Personality, error) {
+	p, ok := personalities[strings.ToLower(name)]
+	if !ok {
+		return Personality{}, fmt.Errorf("invalid personality %q", name)
+	}
+	return p, nil
+}
+
+// PersonalityBot wraps any Player and applies a Personality to its
+// decisions. Lifecycle hooks go straight to the wrapped bot.
+type PersonalityBot struct {
+	Player
+	Personality Personality
+	// Rand drives the personality's choices; a source seeded with 1 is used
+	// when nil.
+	Rand *rand.Rand
+	// Sleep pauses for the thinking delay (default time.Sleep).
+	Sleep func(time.Duration)
+}
+
+// WithPersonality wraps p, or returns it unchanged for the zero Personality.
+func WithPersonality(p Player, pers Personality, seed int64) Player {
+	if pers == (Personality{}) {
+		return p
+	}
+	return &PersonalityBot{Player: p, Personality: pers, Rand: rand.New(rand.NewSource(seed))}
+}
+
+func (b *PersonalityBot) rng() *rand.Rand {
+	if b.Rand == nil {
+		b.Rand = rand.New(rand.NewSource(1))
+	}
+	return b.Rand
+}
+
+// think pauses for the thinking delay.
+func (b *PersonalityBot) think() {
+	d := b.Personality.Delay
+	if d <= 0 {
+		return
+	}
+	d = d/2 + time.Duration(b.rng().Int63n(int64(d)+1))
+	if b.Sleep != nil {
+		b.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+func (b *PersonalityBot) MakeBidDecision(view engine.PlayerView) (int, error) {
+	b.think()
+	bid, err := b.Player.MakeBidDecision(view)
+	if err != nil || len(view.LegalBids) < 2 {
+		return bid, err
+	}
+	next := view.LegalBids[1]
+	limit := float64(EstimateHandValue(view.Hand)) + b.Personality.Risk*50
+	switch {
+	case b.Personality.Risk > 0 && bid == 0 && float64(next) <= limit:
+		return next, nil
+	case b.Personality.Risk < 0 && bid != 0 && float64(bid) > limit:
+		return 0, nil
+	}
+	return bid, nil
+}
+
+func (b *PersonalityBot) ChooseMusik(view engine.PlayerView) (int, error) {
+	b.think()
+	return b.Player.ChooseMusik(view)
+}
+
+func (b *PersonalityBot) ChooseDiscardCards(view engine.PlayerView) ([]engine.Card, error) {
+	b.think()
+	if b.rng().Float64() < b.Personality.ErrorRate {
+		i := b.rng().Perm(len(view.Hand))
+		return []engine.Card{view.Hand[i[0]], view.Hand[i[1]]}, nil
+	}
+	return b.Player.ChooseDiscardCards(view)
+}
+
+func (b *PersonalityBot) PlayCard(view engine.PlayerView) (engine.Card, bool, error) {
+	b.think()
+	card, marriage, err := b.Player.PlayCard(view)
+	if err != nil {
+		return card, marriage, err
+	}
+	if len(view.LegalPlays) > 1 && b.rng().Float64() < b.Personality.ErrorRate {
+		card = view.LegalPlays[b.rng().Intn(len(view.LegalPlays))]
+		marriage = AnnouncesMarriage(view, card)
+	}
+	if marriage && b.rng().Float64() < b.Personality.MissMarriage {
+		marriage = false
+	}
+	return card, marriage, nil
+}
+
+// Close closes the wrapped bot if it holds resources, such as a ProcessBot.
+func (b *PersonalityBot) Close() error {
+	if c, ok := b.Player.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
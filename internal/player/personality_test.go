@@ -0,0 +1,109 @@
+package player_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// leadView returns the view of P2 leading the first trick with the heart
+// marriage in hand.
+func leadView(t *testing.T) engine.PlayerView {
+	t.Helper()
+	g := discardGame(t)
+	cards := (&player.DiscardPlanner{}).Choose(g.View("P2"))
+	if err := g.Discard("P2", cards); err != nil {
+		t.Fatal(err)
+	}
+	return g.View("P2")
+}
+
+func TestPersonalityMissesMarriages(t *testing.T) {
+	view := leadView(t)
+	inner := player.NewHeuristicBot()
+	card, marriage, _ := inner.PlayCard(view)
+	if !marriage {
+		t.Fatalf("HeuristicBot leads %v without the marriage", card)
+	}
+	b := &player.PersonalityBot{Player: inner, Personality: player.Personality{MissMarriage: 1}}
+	if c, m, _ := b.PlayCard(view); c != card || m {
+		t.Fatalf("played %v, marriage %v; want %v unannounced", c, m, card)
+	}
+}
+
+func TestPersonalityBlunders(t *testing.T) {
+	view := leadView(t)
+	want, _, _ := player.NewHeuristicBot().PlayCard(view)
+	b := &player.PersonalityBot{Player: player.NewHeuristicBot(), Personality: player.Personality{ErrorRate: 1}, Rand: rand.New(rand.NewSource(2))}
+	differs := false
+	for range 20 {
+		c, _, _ := b.PlayCard(view)
+		if !slices.Contains(view.LegalPlays, c) {
+			t.Fatalf("illegal blunder %v", c)
+		}
+		differs = differs || c != want
+	}
+	if !differs {
+		t.Fatal("always played the wrapped bot's card")
+	}
+}
+
+// bidder always passes or always raises.
+type bidder struct {
+	player.HeuristicBot
+	raise bool
+}
+
+func (b *bidder) MakeBidDecision(v engine.PlayerView) (int, error) {
+	if b.raise {
+		return v.LegalBids[1], nil
+	}
+	return 0, nil
+}
+
+func TestPersonalityRiskAndDelay(t *testing.T) {
+	view := discardView(t)
+	// two top hearts and the spade ace, too little to bid 110 on its own
+	view.Phase, view.LegalBids, view.Hand = engine.PhaseAuction, []int{0, 110}, parseCards(t, "AH 10H AS 9S JS 9D JD 9C JC QD")
+	value := player.EstimateHandValue(view.Hand)
+	if value != 60 {
+		t.Fatalf("hand valued %d, want 60", value)
+	}
+
+	var slept []time.Duration
+	bold := &player.PersonalityBot{
+		Player:      &bidder{},
+		Personality: player.Personality{Risk: 1, Delay: time.Second},
+		Sleep:       func(d time.Duration) { slept = append(slept, d) },
+	}
+	bid, err := bold.MakeBidDecision(view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bid != 110 {
+		t.Fatalf("risk 1 with a hand worth %d bids %d", value, bid)
+	}
+	if len(slept) != 1 || slept[0] < time.Second/2 || slept[0] > 3*time.Second/2 {
+		t.Fatalf("slept %v", slept)
+	}
+	view.LegalBids = []int{0, value + 10}
+	timid := &player.PersonalityBot{Player: &bidder{raise: true}, Personality: player.Personality{Risk: -0.5}}
+	bid, err = timid.MakeBidDecision(view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bid != 0 {
+		t.Fatalf("risk -0.5 bids %d on a hand worth %d", bid, value)
+	}
+}
+
+func TestWithPersonalityZero(t *testing.T) {
+	b := player.NewHeuristicBot()
+	if player.WithPersonality(b, player.Personality{}, 1) != b {
+		t.Fatal("zero personality wrapped the bot")
+	}
+}
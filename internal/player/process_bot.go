@@ -0,0 +1,381 @@
+package player
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// ProtocolVersion is the version of the bot protocol described in
+// docs/bot_protocol.md.
+const ProtocolVersion = 1
+
+// DefaultProcessTimeout bounds the handshake and each decision of a
+// ProcessBot whose Timeout is zero.
+const DefaultProcessTimeout = 10 * time.Second
+
+// processQueue is how many lines may wait for a ProcessBot to read them; a
+// bot that falls further behind is taken to have stopped reading.
+const processQueue = 256
+
+// Message is one line of the bot protocol, in either direction.
+type Message struct {
+	Type string `json:"type"`
+	// ID pairs a request with its action or error reply.
+	ID      int    `json:"id,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Name    string `json:"name,omitempty"`
+	// Decision is one of "bid", "musik", "discard" or "play".
+	Decision string             `json:"decision,omitempty"`
+	View     *engine.PlayerView `json:"view,omitempty"`
+	Event    *engine.Event      `json:"event,omitempty"`
+
+	Bid      *int          `json:"bid,omitempty"`
+	Musik    *int          `json:"musik,omitempty"`
+	Cards    []engine.Card `json:"cards,omitempty"`
+	Card     *engine.Card  `json:"card,omitempty"`
+	Marriage bool          `json:"marriage,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// ProcessBot is a Player backed by an external process that speaks the bot
+// protocol over its stdin and stdout. The process's stderr is passed through.
+// A bot that exits or breaks the protocol fails every later decision; a
+// decision that times out fails alone and its late reply is discarded. Lines
+// to the process are queued and written in the background, so a bot that
+// stops reading cannot block the game.
+type ProcessBot struct {
+	// Timeout bounds each decision; zero means DefaultProcessTimeout.
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	name   string
+	cmd    *exec.Cmd
+	out    chan []byte
+	lines  chan []byte
+	nextID int
+	err    error
+
+	// writeMu guards writeErr, the sticky failure of the writer.
+	writeMu  sync.Mutex
+	writeErr error
+}
+
+// StartProcessBot starts the command and waits for its ready message.
+func StartProcessBot(command string, args ...string) (*ProcessBot, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	b := &ProcessBot{
+		name:  filepath.Base(command),
+		cmd:   cmd,
+		out:   make(chan []byte, processQueue),
+		lines: make(chan []byte),
+	}
+	go b.write(stdin)
+	go func() {
+		defer close(b.lines)
+		sc := bufio.NewScanner(stdout)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			b.lines <- append([]byte(nil), sc.Bytes()...)
+		}
+	}()
+
+	if err := b.send(Message{Type: "hello", Version: ProtocolVersion}); err != nil {
+		b.Close()
+		return nil, err
+	}
+	ready, err := b.receive(func(m Message) bool { return m.Type == "ready" })
+	if err != nil {
+		b.Close()
+		return nil, fmt.Errorf("%s: handshake: %w", b.name, err)
+	}
+	if ready.Name != "" {
+		b.name = ready.Name
+	}
+	return b, nil
+}
+
+func (b *ProcessBot) Name() string { return b.name }
+
+// Close asks the process to quit and kills it if it does not exit promptly.
+func (b *ProcessBot) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.err = errors.New("bot closed")
+	}
+	cmd := b.cmd
+	if cmd == nil {
+		return nil
+	}
+	b.cmd = nil
+	line, _ := json.Marshal(Message{Type: "quit"})
+	select {
+	case b.out <- append(line, '\n'):
+	default:
+	}
+	// the writer closes stdin once the queue is written
+	close(b.out)
+	// Wait closes stdout, so the reader must reach its end first; killing
+	// the process also frees a writer stuck on a full pipe
+	kill := time.AfterFunc(time.Second, func() { cmd.Process.Kill() })
+	for range b.lines {
+	}
+	kill.Stop()
+	return cmd.Wait()
+}
+
+func (b *ProcessBot) OnHandStart(v engine.PlayerView) {
+	b.notify(Message{Type: "hand_start", View: &v})
+}
+
+func (b *ProcessBot) OnEvent(e engine.Event) {
+	b.notify(Message{Type: "event", Event: &e})
+}
+
+func (b *ProcessBot) OnHandEnd(v engine.PlayerView) {
+	b.notify(Message{Type: "hand_end", View: &v})
+}
+
+func (b *ProcessBot) MakeBidDecision(v engine.PlayerView) (int, error) {
+	m, err := b.request("bid", v)
+	if err != nil {
+		return 0, err
+	}
+	if m.Bid == nil {
+		return 0, fmt.Errorf("%s: bid reply without bid", b.name)
+	}
+	return *m.Bid, nil
+}
+
+func (b *ProcessBot) ChooseMusik(v engine.PlayerView) (int, error) {
+	m, err := b.request("musik", v)
+	if err != nil {
+		return 0, err
+	}
+	if m.Musik == nil {
+		return 0, fmt.Errorf("%s: musik reply without musik", b.name)
+	}
+	return *m.Musik, nil
+}
+
+func (b *ProcessBot) ChooseDiscardCards(v engine.PlayerView) ([]engine.Card, error) {
+	m, err := b.request("discard", v)
+	if err != nil {
+		return nil, err
+	}
+	return m.Cards, nil
+}
+
+func (b *ProcessBot) PlayCard(v engine.PlayerView) (engine.Card, bool, error) {
+	m, err := b.request("play", v)
+	if err != nil {
+		return engine.Card{}, false, err
+	}
+	if m.Card == nil {
+		return engine.Card{}, false, fmt.Errorf("%s: play reply without card", b.name)
+	}
+	return *m.Card, m.Marriage, nil
+}
+
+// notify sends a message that needs no reply; failures surface on the next
+// decision.
+func (b *ProcessBot) notify(m Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.send(m)
+	}
+}
+
+func (b *ProcessBot) request(decision string, v engine.PlayerView) (Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return Message{}, b.err
+	}
+	b.nextID++
+	id := b.nextID
+	if err := b.send(Message{Type: "request", ID: id, Decision: decision, View: &v}); err != nil {
+		return Message{}, err
+	}
+	m, err := b.receive(func(m Message) bool {
+		return (m.Type == "action" || m.Type == "error") && m.ID == id
+	})
+	if err != nil {
+		return Message{}, fmt.Errorf("%s: %s: %w", b.name, decision, err)
+	}
+	if m.Type == "error" {
+		return Message{}, fmt.Errorf("%s: %s: bot error: %s", b.name, decision, m.Error)
+	}
+	return m, nil
+}
+
+// send queues one message line without blocking. A write failure and a
+// full queue are sticky.
+func (b *ProcessBot) send(m Message) error {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	b.writeMu.Lock()
+	err = b.writeErr
+	b.writeMu.Unlock()
+	if err != nil {
+		b.err = fmt.Errorf("%s: write: %w", b.name, err)
+		return b.err
+	}
+	select {
+	case b.out <- append(line, '\n'):
+		return nil
+	default:
+		b.err = fmt.Errorf("%s: bot stopped reading its input", b.name)
+		return b.err
+	}
+}
+
+// write writes queued lines to w until the queue is closed, then closes w.
+// After a failed write it discards the rest.
+func (b *ProcessBot) write(w io.WriteCloser) {
+	defer w.Close()
+	for line := range b.out {
+		if _, err := w.Write(line); err != nil {
+			b.writeMu.Lock()
+			b.writeErr = err
+			b.writeMu.Unlock()
+			for range b.out {
+			}
+			return
+		}
+	}
+}
+
+// receive skips lines until want matches one or the timeout expires.
+func (b *ProcessBot) receive(want func(Message) bool) (Message, error) {
+	timeout := b.Timeout
+	if timeout == 0 {
+		timeout = DefaultProcessTimeout
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		select {
+		case line, ok := <-b.lines:
+			if !ok {
+				b.err = fmt.Errorf("%s: bot exited", b.name)
+				return Message{}, b.err
+			}
+			var m Message
+			if err := json.Unmarshal(line, &m); err != nil {
+				b.err = fmt.Errorf("%s: invalid message %q: %w", b.name, line, err)
+				return Message{}, b.err
+			}
+			if want(m) {
+				return m, nil
+			}
+		case <-deadline.C:
+			return Message{}, fmt.Errorf("no reply within %v", timeout)
+		}
+	}
+}
+
+// Serve runs p as a protocol bot, reading host messages from r and writing
+// replies to w until the host sends quit or closes r. It lets Go players run
+// out of process.
+func Serve(p Player, r io.Reader, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var m Message
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+		var reply *Message
+		switch {
+		case m.Type == "hello":
+			reply = &Message{Type: "ready", Name: p.Name(), Version: ProtocolVersion}
+		case m.Type == "quit":
+			return nil
+		case missing(m) != "":
+			reply = &Message{Type: "error", ID: m.ID, Error: fmt.Sprintf("%s message without %s", m.Type, missing(m))}
+		case m.Type == "hand_start":
+			p.OnHandStart(*m.View)
+		case m.Type == "event":
+			p.OnEvent(*m.Event)
+		case m.Type == "hand_end":
+			p.OnHandEnd(*m.View)
+		case m.Type == "request":
+			reply = serveRequest(p, m)
+		}
+		if reply != nil {
+			if err := enc.Encode(reply); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// missing names the payload a host message lacks, if any.
+func missing(m Message) string {
+	switch m.Type {
+	case "event":
+		if m.Event == nil {
+			return "event"
+		}
+	case "hand_start", "hand_end", "request":
+		if m.View == nil {
+			return "view"
+		}
+	}
+	return ""
+}
+
+func serveRequest(p Player, m Message) *Message {
+	reply := &Message{Type: "action", ID: m.ID}
+	var err error
+	switch m.Decision {
+	case "bid":
+		var bid int
+		bid, err = p.MakeBidDecision(*m.View)
+		reply.Bid = &bid
+	case "musik":
+		var musik int
+		musik, err = p.ChooseMusik(*m.View)
+		reply.Musik = &musik
+	case "discard":
+		reply.Cards, err = p.ChooseDiscardCards(*m.View)
+	case "play":
+		var c engine.Card
+		c, reply.Marriage, err = p.PlayCard(*m.View)
+		reply.Card = &c
+	default:
+		err = fmt.Errorf("unknown decision %q", m.Decision)
+	}
+	if err != nil {
+		return &Message{Type: "error", ID: m.ID, Error: err.Error()}
+	}
+	return reply
+}
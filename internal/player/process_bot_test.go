@@ -0,0 +1,149 @@
+package player_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// TestMain doubles as a protocol bot: with TYS_PROTOCOL_BOT set the test
+// binary serves a player over stdin/stdout instead of running tests.
+func TestMain(m *testing.M) {
+	switch os.Getenv("TYS_PROTOCOL_BOT") {
+	case "":
+		os.Exit(m.Run())
+	case "hang":
+		// answer the handshake, then never decide
+		os.Stdout.WriteString(`{"type":"ready","name":"hang"}` + "\n")
+		time.Sleep(time.Hour)
+	default:
+		if err := player.Serve(&player.HeuristicBot{BotName: "served"}, os.Stdin, os.Stdout); err != nil {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func startProtocolBot(t *testing.T, mode string) (*player.ProcessBot, error) {
+	t.Helper()
+	t.Setenv("TYS_PROTOCOL_BOT", mode)
+	b, err := player.StartProcessBot(os.Args[0])
+	if b != nil {
+		t.Cleanup(func() { b.Close() })
+	}
+	return b, err
+}
+
+func TestProcessBotPlaysHand(t *testing.T) {
+	b, err := startProtocolBot(t, "heuristic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name() != "served" {
+		t.Fatalf("name = %q, want served", b.Name())
+	}
+	players := []engine.PlayerID{"P1", "P2"}
+	params := engine.GameParams{Players: players, MinBid: 100, MinRaise: 10, HandCards: 10, MusiksCount: 2, MusikSize: 2}
+	for seed := int64(1); seed <= 3; seed++ {
+		g := engine.NewGame(params, "P1", players, nil)
+		if err := match.Deal(g, rand.New(rand.NewSource(seed))); err != nil {
+			t.Fatal(err)
+		}
+		bots := map[engine.PlayerID]player.Player{"P1": player.NewHeuristicBot(), "P2": b}
+		if err := match.PlayHand(g, bots); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+func TestProcessBotDecisionTimeout(t *testing.T) {
+	b, err := startProtocolBot(t, "hang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Timeout = 50 * time.Millisecond
+	players := []engine.PlayerID{"P1", "P2"}
+	params := engine.GameParams{Players: players, MinBid: 100, MinRaise: 10, HandCards: 10, MusiksCount: 2, MusikSize: 2}
+	g := engine.NewGame(params, "P1", players, nil)
+	if err := match.Deal(g, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.MakeBidDecision(g.View("P2")); err == nil {
+		t.Fatal("expected a timeout")
+	}
+}
+
+func TestExecBotType(t *testing.T) {
+	t.Setenv("TYS_PROTOCOL_BOT", "heuristic")
+	b, err := player.NewFromSpec("exec:cmd="+os.Args[0]+":2s", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.(*player.ProcessBot).Close()
+	if b.Name() != "served" || b.(*player.ProcessBot).Timeout != 2*time.Second {
+		t.Fatalf("name %q, timeout %v", b.Name(), b.(*player.ProcessBot).Timeout)
+	}
+	for _, spec := range []string{"exec", "exec:cmd=/no/such/bot"} {
+		if _, err := player.NewFromSpec(spec, 1); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestServeRejectsMissingPayloads(t *testing.T) {
+	in := strings.NewReader(`{"type":"hand_start"}` + "\n" +
+		`{"type":"request","id":3,"decision":"bid"}` + "\n" +
+		`{"type":"event"}` + "\n")
+	var out strings.Builder
+	if err := player.Serve(player.NewHeuristicBot(), in, &out); err != nil {
+		t.Fatal(err)
+	}
+	var replies []player.Message
+	dec := json.NewDecoder(strings.NewReader(out.String()))
+	for dec.More() {
+		var m player.Message
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		replies = append(replies, m)
+	}
+	if len(replies) != 3 || replies[1].Type != "error" || replies[1].ID != 3 {
+		t.Fatalf("replies %+v", replies)
+	}
+}
+
+func TestProcessBotNotifyDoesNotBlock(t *testing.T) {
+	b, err := startProtocolBot(t, "hang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := []engine.PlayerID{"P1", "P2"}
+	params := engine.GameParams{Players: players, MinBid: 100, MinRaise: 10, HandCards: 10, MusiksCount: 2, MusikSize: 2}
+	g := engine.NewGame(params, "P1", players, nil)
+	if err := match.Deal(g, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// far more than the pipe and the queue hold
+		for range 5000 {
+			b.OnHandStart(g.View("P2"))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("notifications to a bot that stopped reading blocked")
+	}
+	if _, err := b.MakeBidDecision(g.View("P2")); err == nil || !strings.Contains(err.Error(), "stopped reading") {
+		t.Fatalf("decision error = %v, want the bot to have stopped reading", err)
+	}
+}
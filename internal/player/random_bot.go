@@ -2,42 +2,49 @@ package player
 
 import (
 	"math/rand"
-	"strconv"
 
 	"github.com/ZygmuntJakub/1000/internal/engine"
 )
 
 type RandomBot struct {
+	NopHooks
 	BotName string
+	// Rand drives every choice; a source seeded with 1 is used when nil.
+	Rand *rand.Rand
 }
 
 func (b *RandomBot) Name() string {
 	if b.BotName == "" {
-		b.BotName = "RandomBot_" + strconv.Itoa(rand.Intn(100))
+		return "RandomBot"
 	}
 	return b.BotName
 }
 
-func (b *RandomBot) MakeBidDecision(cards *[]engine.Card, legalBids []int) (int, error) {
-	return legalBids[rand.Intn(len(legalBids))], nil
+func (b *RandomBot) rng() *rand.Rand {
+	if b.Rand == nil {
+		b.Rand = rand.New(rand.NewSource(1))
+	}
+	return b.Rand
 }
 
-func (b *RandomBot) ChooseMusik(numberOfMusiks int) (int, error) {
-	return rand.Intn(numberOfMusiks), nil
+func (b *RandomBot) MakeBidDecision(view engine.PlayerView) (int, error) {
+	return view.LegalBids[b.rng().Intn(len(view.LegalBids))], nil
 }
 
-func (b *RandomBot) ChooseDiscardCards(cards *[]engine.Card, numberOfCards int) (*[]engine.Card, error) {
-	out := make([]engine.Card, numberOfCards)
-	for i := range numberOfCards {
-		out[i] = (*cards)[i]
-	}
-	return &out, nil
+func (b *RandomBot) ChooseMusik(view engine.PlayerView) (int, error) {
+	return b.rng().Intn(view.MusikCount), nil
+}
+
+func (b *RandomBot) ChooseDiscardCards(view engine.PlayerView) ([]engine.Card, error) {
+	i := b.rng().Perm(len(view.Hand))
+	return []engine.Card{view.Hand[i[0]], view.Hand[i[1]]}, nil
 }
 
-func (b *RandomBot) PlayCard(cards *[]engine.Card, playState *engine.PlayState) (*engine.Card, bool, error) {
-	return &(*cards)[rand.Intn(len(*cards))], false, nil
+func (b *RandomBot) PlayCard(view engine.PlayerView) (engine.Card, bool, error) {
+	return view.LegalPlays[b.rng().Intn(len(view.LegalPlays))], false, nil
 }
 
-func NewRandomBot() Player {
-	return &RandomBot{}
+// NewRandomBot returns a RandomBot whose choices are drawn from seed.
+func NewRandomBot(seed int64) Player {
+	return &RandomBot{Rand: rand.New(rand.NewSource(seed))}
 }
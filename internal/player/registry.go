@@ -0,0 +1,355 @@
+package player
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// Difficulty is a coarse strength setting; each bot type documents how it
+// interprets it.
+type Difficulty int
+
+const (
+	Normal Difficulty = iota
+	Easy
+	Hard
+)
+
+var difficultyNames = map[Difficulty]string{Easy: "easy", Normal: "normal", Hard: "hard"}
+
+func (d Difficulty) String() string { return difficultyNames[d] }
+
+// ParseDifficulty parses "easy", "normal" or "hard".
+func ParseDifficulty(s string) (Difficulty, error) {
+	for d, name := range difficultyNames {
+		if strings.EqualFold(s, name) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid difficulty %q", s)
+}
+
+// Config configures a bot created through the registry.
+type Config struct {
+	Difficulty Difficulty
+	// TimeBudget caps thinking time per decision for searching bots; zero
+	// keeps the difficulty's default.
+	TimeBudget time.Duration
+	// Seed seeds the bot's random choices.
+	Seed int64
+	// SeedSet records that the spec pinned Seed, so factories keep it
+	// instead of the seed they are called with.
+	SeedSet bool
+	// Model is the weights file of learned bots.
+	Model string
+	// Command is the program of process bots, followed by its arguments
+	// separated by spaces.
+	Command string
+	// Personality, if not zero, wraps the bot in a PersonalityBot.
+	Personality Personality
+
+	// src is the stream the random sources of one bot are drawn from.
+	src *rand.Rand
+}
+
+// Rand returns a random source for one use by the bot, seeded from a single
+// stream started from Seed, so every use gets its own sequence.
+func (c *Config) Rand() *rand.Rand {
+	if c.src == nil {
+		c.src = rand.New(rand.NewSource(c.Seed))
+	}
+	return rand.New(rand.NewSource(c.src.Int63()))
+}
+
+// BotType is a registered kind of bot.
+type BotType struct {
+	Name        string
+	Description string
+	// New builds a bot, failing when the config's resources cannot be
+	// loaded or started.
+	New func(Config) (Player, error)
+	// Check, if set, rejects configs New cannot build from.
+	Check func(Config) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]BotType{}
+)
+
+// Register adds a bot type. It panics if the name is taken or contains ':'.
+func Register(t BotType) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if t.Name == "" || strings.Contains(t.Name, ":") {
+		panic("player: invalid bot type name " + strconv.Quote(t.Name))
+	}
+	if _, ok := registry[t.Name]; ok {
+		panic("player: bot type " + t.Name + " registered twice")
+	}
+	registry[t.Name] = t
+}
+
+// BotTypes returns the registered bot types sorted by name.
+func BotTypes() []BotType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]BotType, 0, len(registry))
+	for _, t := range registry {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ParseSpec parses a bot spec: a registered name followed by optional
+// ':'-separated settings, each a difficulty ("hard"), a time budget
+// ("500ms"), a seed ("seed=7"), a model file ("model=bot.json"), a command
+// ("cmd=./bot --fast"), a personality preset ("beginner") or a personality
+// trait ("risk=0.5", "errors=0.1", "miss=0.3", "delay=1s"). For example
+// "heuristic:easy", "ismcts:hard:2s:seed=42", "exec:cmd=python3 bot.py" or
+// "heuristic:casual:delay=800ms".
+func ParseSpec(spec string) (BotType, Config, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	registryMu.RLock()
+	t, ok := registry[parts[0]]
+	registryMu.RUnlock()
+	if !ok {
+		return BotType{}, Config{}, fmt.Errorf("unknown bot type %q", parts[0])
+	}
+	var cfg Config
+	for _, opt := range parts[1:] {
+		if d, err := ParseDifficulty(opt); err == nil {
+			cfg.Difficulty = d
+			continue
+		}
+		if d, err := time.ParseDuration(opt); err == nil && d > 0 {
+			cfg.TimeBudget = d
+			continue
+		}
+		if s, ok := strings.CutPrefix(opt, "seed="); ok {
+			seed, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return BotType{}, Config{}, fmt.Errorf("bot spec %q: invalid seed %q", spec, s)
+			}
+			cfg.Seed, cfg.SeedSet = seed, true
+			continue
+		}
+		if path, ok := strings.CutPrefix(opt, "model="); ok {
+			cfg.Model = path
+			continue
+		}
+		if command, ok := strings.CutPrefix(opt, "cmd="); ok {
+			cfg.Command = command
+			continue
+		}
+		if p, err := ParsePersonality(opt); err == nil {
+			delay := cfg.Personality.Delay
+			cfg.Personality = p
+			cfg.Personality.Delay = delay
+			continue
+		}
+		if ok, err := parseTrait(&cfg.Personality, opt); ok {
+			if err != nil {
+				return BotType{}, Config{}, fmt.Errorf("bot spec %q: %w", spec, err)
+			}
+			continue
+		}
+		return BotType{}, Config{}, fmt.Errorf("bot spec %q: unknown setting %q", spec, opt)
+	}
+	if t.Check != nil {
+		if err := t.Check(cfg); err != nil {
+			return BotType{}, Config{}, fmt.Errorf("bot spec %q: %w", spec, err)
+		}
+	}
+	return t, cfg, nil
+}
+
+// NewFromSpec creates a bot from a spec accepted by ParseSpec, seeded with
+// seed unless the spec pins one.
+func NewFromSpec(spec string, seed int64) (Player, error) {
+	t, cfg, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return build(t, cfg, seed)
+}
+
+// FactoryFor validates spec and returns a factory creating bots from it. A
+// bot that fails to build, say because its model file went missing, fails
+// every decision.
+func FactoryFor(spec string) (PlayerFactory, error) {
+	t, cfg, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return func(seed int64) Player {
+		p, err := build(t, cfg, seed)
+		if err != nil {
+			return &brokenBot{name: t.Name, err: err}
+		}
+		return p
+	}, nil
+}
+
+// build creates a bot of type t, seeded with seed unless cfg pins one.
+func build(t BotType, cfg Config, seed int64) (Player, error) {
+	if !cfg.SeedSet {
+		cfg.Seed = seed
+	}
+	// New gets a copy of cfg, so start the stream here to share it
+	cfg.src = rand.New(rand.NewSource(cfg.Seed))
+	p, err := t.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return WithPersonality(p, cfg.Personality, cfg.src.Int63()), nil
+}
+
+// brokenBot stands in for a bot that could not be built.
+type brokenBot struct {
+	NopHooks
+	name string
+	err  error
+}
+
+func (b *brokenBot) Name() string                                                { return b.name }
+func (b *brokenBot) MakeBidDecision(engine.PlayerView) (int, error)              { return 0, b.err }
+func (b *brokenBot) ChooseMusik(engine.PlayerView) (int, error)                  { return 0, b.err }
+func (b *brokenBot) ChooseDiscardCards(engine.PlayerView) ([]engine.Card, error) { return nil, b.err }
+func (b *brokenBot) PlayCard(engine.PlayerView) (engine.Card, bool, error) {
+	return engine.Card{}, false, b.err
+}
+
+// parseTrait sets a personality trait from a "name=value" setting. It
+// reports false when opt names no trait.
+func parseTrait(p *Personality, opt string) (bool, error) {
+	name, value, _ := strings.Cut(opt, "=")
+	if name == "delay" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return true, fmt.Errorf("invalid delay %q", value)
+		}
+		p.Delay = d
+		return true, nil
+	}
+	traits := map[string]*float64{"risk": &p.Risk, "errors": &p.ErrorRate, "miss": &p.MissMarriage}
+	field, ok := traits[name]
+	if !ok {
+		return false, nil
+	}
+	low := 0.0
+	if name == "risk" {
+		low = -1
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < low || v > 1 {
+		return true, fmt.Errorf("invalid %s %q: want %g to 1", name, value, low)
+	}
+	*field = v
+	return true, nil
+}
+
+func init() {
+	Register(BotType{
+		Name:        "random",
+		Description: "plays uniformly random legal moves; ignores difficulty",
+		New: func(c Config) (Player, error) {
+			return &RandomBot{Rand: c.Rand()}, nil
+		},
+	})
+	Register(BotType{
+		Name:        "heuristic",
+		Description: "rule-based play; easy skips card counting and discard planning, hard rolls out discards",
+		New: func(c Config) (Player, error) {
+			return &HeuristicBot{Difficulty: c.Difficulty, Rand: c.Rand()}, nil
+		},
+	})
+	Register(BotType{
+		Name:        "ismcts",
+		Description: "information-set MCTS; 200/1000/4000 iterations for easy/normal/hard, or a time budget",
+		New: func(c Config) (Player, error) {
+			b := &ISMCTSBot{Exploration: 0.7, Rand: c.Rand()}
+			switch c.Difficulty {
+			case Easy:
+				b.Iterations = 200
+			case Hard:
+				b.Iterations = 4000
+			default:
+				b.Iterations = 1000
+			}
+			if c.TimeBudget > 0 {
+				b.Iterations, b.TimeBudget = 0, c.TimeBudget
+			}
+			return b, nil
+		},
+	})
+	Register(BotType{
+		Name:        "neural",
+		Description: "plays cards with a network trained by tys train (model=path); heuristic auction and discards",
+		New: func(c Config) (Player, error) {
+			net, err := LoadModel(c.Model)
+			if err != nil {
+				return nil, err
+			}
+			return &NeuralBot{Net: net, HeuristicBot: HeuristicBot{Difficulty: c.Difficulty, Rand: c.Rand()}}, nil
+		},
+		Check: func(c Config) error {
+			if c.Model == "" {
+				return fmt.Errorf("neural bot needs model=path")
+			}
+			_, err := LoadModel(c.Model)
+			return err
+		},
+	})
+	Register(BotType{
+		Name:        "cfr",
+		Description: "bids from a strategy solved by tys cfr (model=path); heuristic play, difficulty as heuristic",
+		New: func(c Config) (Player, error) {
+			t, err := LoadBidTable(c.Model)
+			if err != nil {
+				return nil, err
+			}
+			return &CFRBot{Table: t, Rand: c.Rand(), HeuristicBot: HeuristicBot{Difficulty: c.Difficulty, Rand: c.Rand()}}, nil
+		},
+		Check: func(c Config) error {
+			if c.Model == "" {
+				return fmt.Errorf("cfr bot needs model=path")
+			}
+			_, err := LoadBidTable(c.Model)
+			return err
+		},
+	})
+	Register(BotType{
+		Name:        "exec",
+		Description: "an external program speaking docs/bot_protocol.md (cmd=program args); a time budget sets its decision timeout",
+		New: func(c Config) (Player, error) {
+			args := strings.Fields(c.Command)
+			if len(args) == 0 {
+				return nil, fmt.Errorf("exec bot needs cmd=program")
+			}
+			b, err := StartProcessBot(args[0], args[1:]...)
+			if err != nil {
+				return nil, err
+			}
+			b.Timeout = c.TimeBudget
+			return b, nil
+		},
+		Check: func(c Config) error {
+			args := strings.Fields(c.Command)
+			if len(args) == 0 {
+				return fmt.Errorf("exec bot needs cmd=program")
+			}
+			_, err := exec.LookPath(args[0])
+			return err
+		},
+	})
+}
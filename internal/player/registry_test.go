@@ -0,0 +1,113 @@
+package player_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+func TestParseSpec(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		name string
+		cfg  player.Config
+	}{
+		{"random", "random", player.Config{}},
+		{"heuristic:hard", "heuristic", player.Config{Difficulty: player.Hard}},
+		{"ismcts:500ms", "ismcts", player.Config{TimeBudget: 500 * time.Millisecond}},
+		{"ismcts:easy:2s:seed=42", "ismcts", player.Config{Difficulty: player.Easy, TimeBudget: 2 * time.Second, Seed: 42, SeedSet: true}},
+		{"heuristic:delay=1s:beginner:miss=0", "heuristic", player.Config{Personality: player.Personality{Risk: 0.3, ErrorRate: 0.25, Delay: time.Second}}},
+		{"random:cautious", "random", player.Config{Personality: player.Personality{Risk: -0.6}}},
+	} {
+		bt, cfg, err := player.ParseSpec(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if bt.Name != tc.name || cfg != tc.cfg {
+			t.Fatalf("%s: got %s %+v, want %s %+v", tc.spec, bt.Name, cfg, tc.name, tc.cfg)
+		}
+	}
+	for _, spec := range []string{"", "nobot", "heuristic:brutal", "random:seed=x", "ismcts:-1s", "heuristic:risk=2", "heuristic:errors=-0.1", "heuristic:delay=soon"} {
+		if _, _, err := player.ParseSpec(spec); err == nil {
+			t.Fatalf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestRegistryConfiguresBots(t *testing.T) {
+	b, err := player.NewFromSpec("ismcts:hard", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := b.(*player.ISMCTSBot); m.Iterations != 4000 || m.TimeBudget != 0 {
+		t.Fatalf("ismcts:hard = %d iterations, %v budget", m.Iterations, m.TimeBudget)
+	}
+	b, _ = player.NewFromSpec("ismcts:hard:250ms", 1)
+	if m := b.(*player.ISMCTSBot); m.Iterations != 0 || m.TimeBudget != 250*time.Millisecond {
+		t.Fatalf("ismcts:hard:250ms = %d iterations, %v budget", m.Iterations, m.TimeBudget)
+	}
+	b, _ = player.NewFromSpec("heuristic:easy", 1)
+	if h := b.(*player.HeuristicBot); h.Difficulty != player.Easy {
+		t.Fatalf("heuristic:easy difficulty = %v", h.Difficulty)
+	}
+}
+
+func TestFactorySeeds(t *testing.T) {
+	f, err := player.FactoryFor("random")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draws := func(b player.Player) []int {
+		r := b.(*player.RandomBot).Rand
+		return []int{r.Intn(1000), r.Intn(1000), r.Intn(1000)}
+	}
+	a, b, c := draws(f(7)), draws(f(7)), draws(f(8))
+	if a[0] != b[0] || a[1] != b[1] || a[2] != b[2] {
+		t.Fatalf("same seed gave %v and %v", a, b)
+	}
+	if a[0] == c[0] && a[1] == c[1] && a[2] == c[2] {
+		t.Fatalf("seeds 7 and 8 gave the same draws %v", a)
+	}
+	pinned, _ := player.FactoryFor("random:seed=3")
+	if draws(pinned(1))[0] != draws(pinned(2))[0] {
+		t.Fatal("a pinned seed was overridden by the factory seed")
+	}
+}
+
+func TestBotSourcesAreIndependent(t *testing.T) {
+	var second *rand.Rand
+	player.Register(player.BotType{Name: "test-pair", New: func(c player.Config) (player.Player, error) {
+		b := player.NewRandomBot(0).(*player.RandomBot)
+		b.Rand = c.Rand()
+		second = c.Rand()
+		return b, nil
+	}})
+	b, err := player.NewFromSpec("test-pair", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.(*player.RandomBot).Rand.Int63() == second.Int63() {
+		t.Fatal("two sources of one bot draw the same stream")
+	}
+}
+
+func TestBrokenBotsFail(t *testing.T) {
+	player.Register(player.BotType{Name: "test-broken", New: func(player.Config) (player.Player, error) {
+		return nil, errors.New("no model")
+	}})
+	if _, err := player.NewFromSpec("test-broken", 1); err == nil {
+		t.Fatal("NewFromSpec built a broken bot")
+	}
+	f, err := player.FactoryFor("test-broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f(1).MakeBidDecision(engine.PlayerView{LegalBids: []int{0}}); err == nil {
+		t.Fatal("a bot that failed to build made a decision")
+	}
+}
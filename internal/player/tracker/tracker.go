@@ -0,0 +1,215 @@
+// Package tracker keeps one seat's card-counting knowledge during a hand:
+// which cards are gone, which suits each opponent has shown void, and which
+// marriages are still possible.
+package tracker
+
+import "github.com/ZygmuntJakub/1000/internal/engine"
+
+// Tracker accumulates what a seat has seen. Feed it with Observe from
+// Player.OnEvent, or rebuild it from a view with FromView.
+type Tracker struct {
+	seat      engine.PlayerID
+	hand      map[engine.Card]bool
+	discards  map[engine.Card]bool
+	played    map[engine.Card]engine.PlayerID
+	voids     map[engine.PlayerID]map[engine.Suit]bool
+	marriages []engine.Marriage
+	trump     *engine.Suit
+	led       *engine.Suit
+}
+
+// New returns an empty tracker for seat.
+func New(seat engine.PlayerID) *Tracker {
+	return &Tracker{
+		seat:     seat,
+		hand:     map[engine.Card]bool{},
+		discards: map[engine.Card]bool{},
+		played:   map[engine.Card]engine.PlayerID{},
+		voids:    map[engine.PlayerID]map[engine.Suit]bool{},
+	}
+}
+
+// FromView builds a tracker from the trick history in view.
+func FromView(view engine.PlayerView) *Tracker {
+	t := New(view.Seat)
+	for _, tr := range view.CompletedTricks {
+		for _, p := range tr.Plays {
+			t.observePlay(p)
+		}
+		t.led = nil
+	}
+	for _, p := range view.CurrentTrick.Plays {
+		t.observePlay(p)
+	}
+	t.Sync(view)
+	return t
+}
+
+// Sync refreshes the seat's own hand and discards from view, which is how
+// the declarer learns the musik it picked up.
+func (t *Tracker) Sync(view engine.PlayerView) {
+	t.hand = map[engine.Card]bool{}
+	for _, c := range view.Hand {
+		t.hand[c] = true
+	}
+	for _, c := range view.OwnDiscards {
+		t.discards[c] = true
+	}
+}
+
+// Observe updates the tracker with an event redacted for its seat.
+func (t *Tracker) Observe(ev engine.Event) {
+	a := ev.Action
+	switch a.Type {
+	case engine.ActionDeal:
+		*t = *New(t.seat)
+		for _, c := range a.Hands[t.seat] {
+			t.hand[c] = true
+		}
+	case engine.ActionDiscard:
+		for _, c := range a.Cards {
+			delete(t.hand, c)
+			t.discards[c] = true
+		}
+	case engine.ActionPlayCard:
+		p := engine.Play{Player: a.Player, Card: a.Card}
+		if a.Marriage {
+			s := a.Card.Suit
+			p.AnnouncedMarriage = &s
+		}
+		t.observePlay(p)
+		if ev.Trick != nil {
+			t.led = nil
+		}
+	}
+}
+
+func (t *Tracker) observePlay(p engine.Play) {
+	t.played[p.Card] = p.Player
+	delete(t.hand, p.Card)
+	if t.led == nil {
+		s := p.Card.Suit
+		t.led = &s
+	} else if p.Card.Suit != *t.led {
+		if t.voids[p.Player] == nil {
+			t.voids[p.Player] = map[engine.Suit]bool{}
+		}
+		t.voids[p.Player][*t.led] = true
+	}
+	if p.AnnouncedMarriage != nil {
+		s := *p.AnnouncedMarriage
+		t.trump = &s
+		t.marriages = append(t.marriages, engine.Marriage{Player: p.Player, Suit: s})
+	}
+}
+
+// Hand returns the seat's own cards.
+func (t *Tracker) Hand() []engine.Card {
+	return t.filter(func(c engine.Card) bool { return t.hand[c] })
+}
+
+// Unseen returns the cards the seat has not seen: the opponents' hands and
+// the face-down table cards.
+func (t *Tracker) Unseen() []engine.Card { return t.filter(t.isUnseen) }
+
+func (t *Tracker) isUnseen(c engine.Card) bool {
+	_, played := t.played[c]
+	return !played && !t.hand[c] && !t.discards[c]
+}
+
+func (t *Tracker) filter(keep func(engine.Card) bool) []engine.Card {
+	var out []engine.Card
+	for _, s := range engine.Suits {
+		for _, r := range engine.RankOrder {
+			if c := (engine.Card{Suit: s, Rank: r}); keep(c) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// PlayedBy reports who played c, if anyone has.
+func (t *Tracker) PlayedBy(c engine.Card) (engine.PlayerID, bool) {
+	p, ok := t.played[c]
+	return p, ok
+}
+
+// Trump returns the current trump suit, or nil.
+func (t *Tracker) Trump() *engine.Suit { return t.trump }
+
+// Marriages returns the marriages announced so far.
+func (t *Tracker) Marriages() []engine.Marriage { return t.marriages }
+
+// IsVoid reports whether p has failed to follow suit s.
+func (t *Tracker) IsVoid(p engine.PlayerID, s engine.Suit) bool { return t.voids[p][s] }
+
+// Voids returns the suits p has shown void in.
+func (t *Tracker) Voids(p engine.PlayerID) []engine.Suit {
+	var out []engine.Suit
+	for _, s := range engine.Suits {
+		if t.voids[p][s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// CanHold reports whether opponent p may still hold c.
+func (t *Tracker) CanHold(p engine.PlayerID, c engine.Card) bool {
+	if p == t.seat {
+		return t.hand[c]
+	}
+	return t.isUnseen(c) && !t.voids[p][c.Suit]
+}
+
+// CanHoldTrump reports whether p may still hold a card of the trump suit.
+func (t *Tracker) CanHoldTrump(p engine.PlayerID) bool {
+	if t.trump == nil {
+		return false
+	}
+	for _, r := range engine.RankOrder {
+		if t.CanHold(p, engine.Card{Suit: *t.trump, Rank: r}) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHighestRemaining reports whether no card that is still out beats c in
+// its own suit. Cards in the seat's hand do not count as out.
+func (t *Tracker) IsHighestRemaining(c engine.Card) bool {
+	above := false
+	for _, r := range engine.RankOrder {
+		if r == c.Rank {
+			above = true
+			continue
+		}
+		if above && t.isUnseen(engine.Card{Suit: c.Suit, Rank: r}) {
+			return false
+		}
+	}
+	return true
+}
+
+// PossibleMarriages returns the suits in which p may still announce a
+// marriage: both king and queen unplayed and possibly in p's hand.
+func (t *Tracker) PossibleMarriages(p engine.PlayerID) []engine.Suit {
+	var out []engine.Suit
+	for _, s := range engine.Suits {
+		if t.CanHold(p, engine.Card{Suit: s, Rank: engine.King}) && t.CanHold(p, engine.Card{Suit: s, Rank: engine.Queen}) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// RemainingPoints returns the card points not yet played, including those
+// of the table cards that go to the last trick winner.
+func (t *Tracker) RemainingPoints() int {
+	pts := 120
+	for c := range t.played {
+		pts -= engine.PointsFor(c.Rank)
+	}
+	return pts
+}
@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+func c(s engine.Suit, r engine.Rank) engine.Card { return engine.Card{Suit: s, Rank: r} }
+
+func TestTrackerFollowsEvents(t *testing.T) {
+	const (
+		S, C, D, H = engine.Spades, engine.Clubs, engine.Diamonds, engine.Hearts
+	)
+	players := []engine.PlayerID{"P1", "P2"}
+	g := engine.NewGame(engine.GameParams{}, "P2", players, nil)
+	p1 := []engine.Card{c(S, engine.Ace), c(S, engine.Nine), c(H, engine.King), c(H, engine.Queen), c(C, engine.Nine), c(C, engine.Jack), c(C, engine.Queen), c(D, engine.Nine), c(D, engine.Jack), c(D, engine.Queen)}
+	p2 := []engine.Card{c(H, engine.Ace), c(H, engine.Ten), c(H, engine.Nine), c(H, engine.Jack), c(C, engine.King), c(C, engine.Ten), c(C, engine.Ace), c(D, engine.King), c(D, engine.Ten), c(D, engine.Ace)}
+	musiks := [][]engine.Card{{c(S, engine.Jack), c(S, engine.Queen)}, {c(S, engine.King), c(S, engine.Ten)}}
+
+	tr := New("P1")
+	step := func(a engine.Action) {
+		t.Helper()
+		ev, err := g.Step(a)
+		if err != nil {
+			t.Fatalf("%s: %v", a.Type, err)
+		}
+		tr.Observe(ev.For("P1"))
+	}
+	step(engine.Action{Type: engine.ActionDeal, Hands: map[engine.PlayerID][]engine.Card{"P1": p1, "P2": p2}, Musiks: musiks})
+	step(engine.Action{Type: engine.ActionBid, Player: "P1", Value: 0})
+	step(engine.Action{Type: engine.ActionChooseMusik, Player: "P2", Index: 1})
+	step(engine.Action{Type: engine.ActionDiscard, Player: "P2", Cards: []engine.Card{c(S, engine.King), c(S, engine.Ten)}})
+
+	if len(tr.Unseen()) != 14 {
+		t.Fatalf("expected 14 unseen cards, got %d", len(tr.Unseen()))
+	}
+	// P1 holds the other queens, so only the face-down spades can pair up
+	if got := tr.PossibleMarriages("P2"); len(got) != 1 || got[0] != S {
+		t.Fatalf("expected only a spades marriage possible for P2, got %v", got)
+	}
+	step(engine.Action{Type: engine.ActionPlayCard, Player: "P2", Card: c(H, engine.Nine)})
+	step(engine.Action{Type: engine.ActionPlayCard, Player: "P1", Card: c(H, engine.Queen)})
+	if tr.IsHighestRemaining(c(H, engine.King)) {
+		t.Fatalf("hearts king is not highest while ace and ten are out")
+	}
+	// P1 won with the queen and leads the spade ace; P2 cannot follow
+	step(engine.Action{Type: engine.ActionPlayCard, Player: "P1", Card: c(S, engine.Ace)})
+	step(engine.Action{Type: engine.ActionPlayCard, Player: "P2", Card: c(D, engine.Ten)})
+	if !tr.IsVoid("P2", S) || tr.IsVoid("P2", D) {
+		t.Fatalf("expected P2 void in spades only, got %v", tr.Voids("P2"))
+	}
+	if tr.CanHold("P2", c(S, engine.Jack)) {
+		t.Fatalf("P2 cannot hold spades after showing void")
+	}
+	if tr.CanHoldTrump("P2") {
+		t.Fatalf("no trump has been announced")
+	}
+	if got := tr.RemainingPoints(); got != 120-3-11-10 {
+		t.Fatalf("expected %d remaining points, got %d", 120-3-11-10, got)
+	}
+	if got := tr.PossibleMarriages("P2"); len(got) != 0 {
+		t.Fatalf("expected no marriage possible for P2 once void in spades, got %v", got)
+	}
+	if got := FromView(g.View("P1")); len(got.Unseen()) != len(tr.Unseen()) || !got.IsVoid("P2", S) {
+		t.Fatalf("FromView disagrees with observed events")
+	}
+}
@@ -2,12 +2,30 @@ package player
 
 import "github.com/ZygmuntJakub/1000/internal/engine"
 
+// Player makes decisions for one seat. Every decision receives the seat's
+// redacted view of the game; legal options are in PlayerView.LegalBids and
+// PlayerView.LegalPlays.
 type Player interface {
 	Name() string
-	MakeBidDecision(*[]engine.Card, []int) (int, error)
-	ChooseMusik(int) (int, error)
-	ChooseDiscardCards(*[]engine.Card, int) (*[]engine.Card, error)
-	PlayCard(*[]engine.Card, *engine.PlayState) (*engine.Card, bool, error)
+	// OnHandStart is called once the cards are dealt.
+	OnHandStart(engine.PlayerView)
+	// OnEvent is called after every accepted action, redacted for the seat.
+	OnEvent(engine.Event)
+	// OnHandEnd is called once the hand is scored.
+	OnHandEnd(engine.PlayerView)
+	MakeBidDecision(engine.PlayerView) (int, error)
+	ChooseMusik(engine.PlayerView) (int, error)
+	ChooseDiscardCards(engine.PlayerView) ([]engine.Card, error)
+	PlayCard(engine.PlayerView) (engine.Card, bool, error)
 }
 
-type PlayerFactory func() Player
+// PlayerFactory creates a bot whose random choices are drawn from seed, so
+// the same seed reproduces the same play.
+type PlayerFactory func(seed int64) Player
+
+// NopHooks implements the Player lifecycle hooks as no-ops for embedding.
+type NopHooks struct{}
+
+func (NopHooks) OnHandStart(engine.PlayerView) {}
+func (NopHooks) OnEvent(engine.Event)          {}
+func (NopHooks) OnHandEnd(engine.PlayerView)   {}
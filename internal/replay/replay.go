@@ -0,0 +1,216 @@
+// Package replay re-runs recorded action logs through the engine and reports
+// the first step where the engine no longer agrees with the recording.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// Step is a recorded action and, optionally, the state it produced.
+type Step struct {
+	Action engine.Action
+	// State is the snapshot taken after Action; nil skips the check.
+	State *engine.GameState `json:",omitempty"`
+}
+
+// Log is everything needed to rebuild a hand from NewGame onwards.
+type Log struct {
+	Params     engine.GameParams
+	Dealer     engine.PlayerID
+	Players    []engine.PlayerID
+	Cumulative map[engine.PlayerID]int
+	Steps      []Step
+}
+
+// Load decodes a JSON log.
+func Load(r io.Reader) (*Log, error) {
+	var l Log
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Save encodes the log as indented JSON.
+func (l *Log) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// NewGame builds the game the log starts from.
+func (l *Log) NewGame() *engine.GameState {
+	return engine.NewGame(l.Params, l.Dealer, append([]engine.PlayerID{}, l.Players...), l.Cumulative)
+}
+
+// Recorder appends every applied action, with a snapshot, to Log.
+type Recorder struct {
+	Log  Log
+	Game *engine.GameState
+}
+
+// NewRecorder starts recording a new game.
+func NewRecorder(params engine.GameParams, dealer engine.PlayerID, players []engine.PlayerID, cumulative map[engine.PlayerID]int) *Recorder {
+	r := &Recorder{Log: Log{Params: params, Dealer: dealer, Players: append([]engine.PlayerID{}, players...)}}
+	if cumulative != nil {
+		r.Log.Cumulative = map[engine.PlayerID]int{}
+		for p, v := range cumulative {
+			r.Log.Cumulative[p] = v
+		}
+	}
+	r.Game = r.Log.NewGame()
+	return r
+}
+
+// Apply applies a to the recorded game and logs it if the engine accepts it.
+func (r *Recorder) Apply(a engine.Action) error {
+	a = copyAction(a)
+	if err := r.Game.Apply(a); err != nil {
+		return err
+	}
+	r.Log.Steps = append(r.Log.Steps, Step{Action: a, State: r.Game.Clone()})
+	return nil
+}
+
+// copyAction detaches a from card slices owned by the game, which the
+// engine mutates in place.
+func copyAction(a engine.Action) engine.Action {
+	if a.Hands != nil {
+		hands := make(map[engine.PlayerID][]engine.Card, len(a.Hands))
+		for p, h := range a.Hands {
+			hands[p] = append([]engine.Card{}, h...)
+		}
+		a.Hands = hands
+	}
+	if a.Musiks != nil {
+		musiks := make([][]engine.Card, len(a.Musiks))
+		for i, m := range a.Musiks {
+			musiks[i] = append([]engine.Card{}, m...)
+		}
+		a.Musiks = musiks
+	}
+	a.Cards = append([]engine.Card(nil), a.Cards...)
+	return a
+}
+
+// Divergence describes the first step where replay and recording disagree.
+type Divergence struct {
+	Step   int // zero-based index into Log.Steps
+	Action engine.Action
+	// Err is set when the engine rejected a recorded action.
+	Err error
+	// Diffs lists state fields that differ from the recorded snapshot.
+	Diffs []string
+}
+
+func (d *Divergence) Error() string {
+	if d.Err != nil {
+		return fmt.Sprintf("step %d (%s by %s): engine rejected action: %v", d.Step, d.Action.Type, d.Action.Player, d.Err)
+	}
+	return fmt.Sprintf("step %d (%s by %s): state differs from recording:\n  %s", d.Step, d.Action.Type, d.Action.Player, strings.Join(d.Diffs, "\n  "))
+}
+
+// Run replays the log and returns the final state. The error is a
+// *Divergence when the engine rejects an action or produces a state that
+// differs from a recorded snapshot.
+func Run(l *Log) (*engine.GameState, error) {
+	g := l.NewGame()
+	for i, s := range l.Steps {
+		if err := g.Apply(s.Action); err != nil {
+			return g, &Divergence{Step: i, Action: s.Action, Err: err}
+		}
+		if s.State == nil {
+			continue
+		}
+		if diffs := Diff(s.State, g); len(diffs) > 0 {
+			return g, &Divergence{Step: i, Action: s.Action, Diffs: diffs}
+		}
+	}
+	return g, nil
+}
+
+// Diff lists the fields in which got differs from want, one line per field.
+// Nil and empty slices and maps are treated as equal.
+func Diff(want, got *engine.GameState) []string {
+	var out []string
+	diffValue("", reflect.ValueOf(want).Elem(), reflect.ValueOf(got).Elem(), &out)
+	return out
+}
+
+var cardType = reflect.TypeOf(engine.Card{})
+
+func diffValue(path string, want, got reflect.Value, out *[]string) {
+	report := func(w, g any) {
+		*out = append(*out, fmt.Sprintf("%s: recorded %v, replayed %v", path, w, g))
+	}
+	if want.Type() == cardType {
+		w, g := want.Interface().(engine.Card), got.Interface().(engine.Card)
+		if w != g {
+			report(w.Code(), g.Code())
+		}
+		return
+	}
+	switch want.Kind() {
+	case reflect.Pointer:
+		switch {
+		case want.IsNil() && got.IsNil():
+		case want.IsNil():
+			report("nil", got.Elem().Interface())
+		case got.IsNil():
+			report(want.Elem().Interface(), "nil")
+		default:
+			diffValue(path, want.Elem(), got.Elem(), out)
+		}
+	case reflect.Struct:
+		for i := range want.NumField() {
+			name := want.Type().Field(i).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValue(name, want.Field(i), got.Field(i), out)
+		}
+	case reflect.Slice:
+		if want.Len() != got.Len() {
+			report(fmt.Sprintf("%d items", want.Len()), fmt.Sprintf("%d items", got.Len()))
+		}
+		for i := range min(want.Len(), got.Len()) {
+			diffValue(fmt.Sprintf("%s[%d]", path, i), want.Index(i), got.Index(i), out)
+		}
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range append(want.MapKeys(), got.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for n := range keys {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			k := keys[n]
+			w, g := want.MapIndex(k), got.MapIndex(k)
+			p := fmt.Sprintf("%s[%s]", path, n)
+			switch {
+			case !w.IsValid():
+				*out = append(*out, fmt.Sprintf("%s: missing in recording, replayed %v", p, g.Interface()))
+			case !g.IsValid():
+				*out = append(*out, fmt.Sprintf("%s: recorded %v, missing in replay", p, w.Interface()))
+			default:
+				diffValue(p, w, g, out)
+			}
+		}
+	default:
+		if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+			report(want.Interface(), got.Interface())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+)
+
+// recordRandomHand plays a hand with random legal actions.
+func recordRandomHand(t *testing.T, seed int64) *Recorder {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	players := []engine.PlayerID{"P1", "P2"}
+	r := NewRecorder(engine.GameParams{}, "P1", players, map[engine.PlayerID]int{"P1": 300, "P2": 450})
+	deck := match.NewDeck()
+	match.Shuffle(deck, rng)
+	apply := func(a engine.Action) {
+		if err := r.Apply(a); err != nil {
+			t.Fatalf("%s: %v", a.Type, err)
+		}
+	}
+	apply(engine.Action{Type: engine.ActionDeal, Hands: map[engine.PlayerID][]engine.Card{"P1": deck[:10], "P2": deck[10:20]}, Musiks: [][]engine.Card{deck[20:22], deck[22:24]}})
+	g := r.Game
+	for g.Phase == engine.PhaseAuction {
+		bids := g.LegalBids(g.Auction.CurrentLeader)
+		apply(engine.Action{Type: engine.ActionBid, Player: g.Auction.CurrentLeader, Value: bids[rng.Intn(len(bids))]})
+	}
+	apply(engine.Action{Type: engine.ActionChooseMusik, Player: *g.Declarer, Index: rng.Intn(2)})
+	apply(engine.Action{Type: engine.ActionDiscard, Player: *g.Declarer, Cards: g.Deal.Hands[*g.Declarer][:2]})
+	for g.Phase == engine.PhasePlay {
+		p := g.CurrentTurnPlayer()
+		legal := g.LegalPlays(p)
+		apply(engine.Action{Type: engine.ActionPlayCard, Player: p, Card: legal[rng.Intn(len(legal))]})
+	}
+	return r
+}
+
+func TestRunMatchesRecording(t *testing.T) {
+	for seed := int64(1); seed <= 10; seed++ {
+		r := recordRandomHand(t, seed)
+		var buf bytes.Buffer
+		if err := r.Log.Save(&buf); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		l, err := Load(&buf)
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		g, err := Run(l)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if d := Diff(r.Game, g); len(d) > 0 {
+			t.Fatalf("seed %d: final state differs: %v", seed, d)
+		}
+	}
+}
+
+func TestRunReportsFirstDivergence(t *testing.T) {
+	r := recordRandomHand(t, 3)
+	last := len(r.Log.Steps) - 1
+	r.Log.Steps[last].State.Scores.Cumulative["P2"] += 10
+	_, err := Run(&r.Log)
+	var d *Divergence
+	if !errors.As(err, &d) {
+		t.Fatalf("expected divergence, got %v", err)
+	}
+	if d.Step != last {
+		t.Fatalf("expected divergence at step %d, got %d", last, d.Step)
+	}
+	if len(d.Diffs) != 1 || !strings.HasPrefix(d.Diffs[0], "Scores.Cumulative[P2]") {
+		t.Fatalf("unexpected diffs: %v", d.Diffs)
+	}
+}
+
+func TestRunReportsRejectedAction(t *testing.T) {
+	r := recordRandomHand(t, 4)
+	r.Log.Steps[1].Action.Value = 105
+	_, err := Run(&r.Log)
+	var d *Divergence
+	if !errors.As(err, &d) || d.Step != 1 || d.Err == nil {
+		t.Fatalf("expected rejected bid at step 1, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+// Package rules reads rules profiles: named sets of the variant options the
+// engine supports, stored as JSON and mapped onto engine.GameParams.
+//
+// A profile file looks like
+//
+//	{
+//	  "name": "house",
+//	  "description": "short games to 500",
+//	  "deal": {"handCards": 10, "musiks": 2, "musikSize": 2},
+//	  "auction": {"minBid": 100, "minRaise": 10, "maxBidWithoutMarriage": 120},
+//	  "marriage": {"notOnFirstTrick": true},
+//	  "scoring": {"roundTo": 10},
+//	  "redeal": {"fourNines": true},
+//	  "barrel": {"at": 880},
+//	  "game": {"targetScore": 500}
+//	}
+//
+// Omitted options keep the engine's defaults, the rules of docs/rules.md.
+// Unknown keys are rejected.
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// Profile is a named set of rules.
+type Profile struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Deal        Deal     `json:"deal"`
+	Auction     Auction  `json:"auction"`
+	Marriage    Marriage `json:"marriage"`
+	Scoring     Scoring  `json:"scoring"`
+	Redeal      Redeal   `json:"redeal"`
+	Barrel      Barrel   `json:"barrel"`
+	Game        Game     `json:"game"`
+}
+
+// Deal sets how the 24 cards are dealt.
+type Deal struct {
+	// HandCards is the number of cards per player (default 10).
+	HandCards int `json:"handCards,omitempty"`
+	// Musiks is the number of musiks on the table (default 2).
+	Musiks int `json:"musiks,omitempty"`
+	// MusikSize is the number of cards per musik, and so of the declarer's
+	// discards; only 2 is supported, as the engine's move generation and
+	// the bots discard pairs.
+	MusikSize int `json:"musikSize,omitempty"`
+}
+
+// Auction sets the bidding limits.
+type Auction struct {
+	// MinBid is the dealer's automatic opening bid (default 100).
+	MinBid int `json:"minBid,omitempty"`
+	// MinRaise is the smallest raise over the highest bid (default 10).
+	MinRaise int `json:"minRaise,omitempty"`
+	// MaxBid caps every bid (default none).
+	MaxBid int `json:"maxBid,omitempty"`
+	// MaxBidWithoutMarriage caps the bids of a player holding no marriage
+	// (default none).
+	MaxBidWithoutMarriage int `json:"maxBidWithoutMarriage,omitempty"`
+}
+
+// Marriage sets when marriages may be announced.
+type Marriage struct {
+	// NotOnFirstTrick forbids announcing a marriage on the first trick, so
+	// only a player who has taken a trick can.
+	NotOnFirstTrick bool `json:"notOnFirstTrick,omitempty"`
+}
+
+// Scoring sets how deal points are settled.
+type Scoring struct {
+	// RoundTo rounds the defenders' deal points to the nearest multiple,
+	// halves up (default exact).
+	RoundTo int `json:"roundTo,omitempty"`
+}
+
+// Redeal sets which hands have the cards dealt again.
+type Redeal struct {
+	// FourNines redeals when a player is dealt all four nines.
+	FourNines bool `json:"fourNines,omitempty"`
+	// BelowPoints redeals when a hand is worth fewer card points (default
+	// none).
+	BelowPoints int `json:"belowPoints,omitempty"`
+}
+
+// Barrel sets the barrel: the score from which a player can only go out by
+// making a contract.
+type Barrel struct {
+	// At is the barrel score (default none); defending never takes a score
+	// above it or raises one already there.
+	At int `json:"at,omitempty"`
+}
+
+// Game sets when the game ends.
+type Game struct {
+	// TargetScore is the cumulative score that wins the game (default 1000).
+	TargetScore int `json:"targetScore,omitempty"`
+}
+
+var (
+	standardDeal    = Deal{HandCards: 10, Musiks: 2, MusikSize: 2}
+	standardAuction = Auction{MinBid: 100, MinRaise: 10}
+)
+
+// builtin are the profiles available by name.
+var builtin = []Profile{
+	{
+		Name:        "PL standard",
+		Description: "two-player tysiąc as in docs/rules.md: 10 cards, two musiks of 2, bids from 100 in tens, game to 1000",
+		Deal:        standardDeal,
+		Auction:     standardAuction,
+		Game:        Game{TargetScore: 1000},
+	},
+	{
+		Name:        "Kurnik",
+		Description: "two-player tysiąc as played on kurnik.pl: bids over 120 need a marriage, no marriage on the first trick, defenders' points rounded to tens, four nines redealt, barrel at 880",
+		Deal:        standardDeal,
+		Auction:     Auction{MinBid: 100, MinRaise: 10, MaxBidWithoutMarriage: 120},
+		Marriage:    Marriage{NotOnFirstTrick: true},
+		Scoring:     Scoring{RoundTo: 10},
+		Redeal:      Redeal{FourNines: true},
+		Barrel:      Barrel{At: 880},
+		Game:        Game{TargetScore: 1000},
+	},
+	{
+		Name:        "house",
+		Description: "a shorter game for an evening: standard deal and bidding, game to 500",
+		Deal:        standardDeal,
+		Auction:     standardAuction,
+		Game:        Game{TargetScore: 500},
+	},
+}
+
+// Builtin returns the built-in profiles.
+func Builtin() []Profile {
+	return append([]Profile(nil), builtin...)
+}
+
+// Load returns the built-in profile called name, ignoring case, spaces,
+// dashes and underscores ("pl-standard" finds "PL standard"), or else reads
+// the profile file at that path.
+func Load(name string) (Profile, error) {
+	for _, p := range builtin {
+		if key(p.Name) == key(name) {
+			return p, nil
+		}
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		var names []string
+		for _, p := range builtin {
+			names = append(names, p.Name)
+		}
+		return Profile{}, fmt.Errorf("rules %q: not a built-in profile (%s) or readable file: %w", name, strings.Join(names, ", "), err)
+	}
+	p, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return Profile{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
+}
+
+func key(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// Parse reads a profile, rejecting unknown keys and invalid rules.
+func Parse(r io.Reader) (Profile, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return Profile{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var p Profile
+	if err := dec.Decode(&p); err != nil {
+		return Profile{}, err
+	}
+	if err := p.Validate(); err != nil {
+		return Profile{}, err
+	}
+	return p, nil
+}
+
+// Validate checks that the options are positive, supported, deal the whole
+// deck and fit together.
+func (p Profile) Validate() error {
+	for _, o := range []struct {
+		name  string
+		value int
+	}{
+		{"handCards", p.Deal.HandCards}, {"musiks", p.Deal.Musiks}, {"musikSize", p.Deal.MusikSize},
+		{"minBid", p.Auction.MinBid}, {"minRaise", p.Auction.MinRaise}, {"maxBid", p.Auction.MaxBid},
+		{"maxBidWithoutMarriage", p.Auction.MaxBidWithoutMarriage}, {"roundTo", p.Scoring.RoundTo},
+		{"belowPoints", p.Redeal.BelowPoints}, {"at", p.Barrel.At}, {"targetScore", p.Game.TargetScore},
+	} {
+		if o.value < 0 {
+			return fmt.Errorf("%s is %d, want a positive value", o.name, o.value)
+		}
+	}
+	params := p.Effective()
+	if params.MusikSize != 2 {
+		return fmt.Errorf("musikSize is %d, but only musiks of 2 are supported", params.MusikSize)
+	}
+	dealt := 2*params.HandCards + params.MusiksCount*params.MusikSize
+	if dealt != 24 {
+		return fmt.Errorf("deal uses %d cards of the 24-card deck: 2 hands of %d and %d musiks of %d",
+			dealt, params.HandCards, params.MusiksCount, params.MusikSize)
+	}
+	for _, o := range []struct {
+		name  string
+		limit int
+	}{{"maxBid", params.MaxBid}, {"maxBidWithoutMarriage", params.MaxBidWithoutMarriage}} {
+		if o.limit != 0 && o.limit < params.MinBid {
+			return fmt.Errorf("%s is %d, below the opening bid of %d", o.name, o.limit, params.MinBid)
+		}
+	}
+	if params.RedealBelow > maxRedealBelow {
+		return fmt.Errorf("belowPoints is %d, but redeals are only supported below %d points", params.RedealBelow, maxRedealBelow)
+	}
+	if params.BarrelAt != 0 && params.BarrelAt >= params.MaxGamePoints {
+		return fmt.Errorf("barrel at %d does not lie below the target score of %d", params.BarrelAt, params.MaxGamePoints)
+	}
+	return nil
+}
+
+// maxRedealBelow keeps redeals rare enough that a deal is always found: an
+// average hand holds 50 of the 120 card points.
+const maxRedealBelow = 30
+
+// Params returns the engine parameters of the profile; omitted options stay
+// zero for engine.NewGame to default and Players is left for the caller.
+func (p Profile) Params() engine.GameParams {
+	return engine.GameParams{
+		MinBid:        p.Auction.MinBid,
+		MinRaise:      p.Auction.MinRaise,
+		HandCards:     p.Deal.HandCards,
+		MusiksCount:   p.Deal.Musiks,
+		MusikSize:     p.Deal.MusikSize,
+		MaxGamePoints: p.Game.TargetScore,
+
+		MaxBid:                 p.Auction.MaxBid,
+		MaxBidWithoutMarriage:  p.Auction.MaxBidWithoutMarriage,
+		NoMarriageOnFirstTrick: p.Marriage.NotOnFirstTrick,
+		ScoreRounding:          p.Scoring.RoundTo,
+		RedealFourNines:        p.Redeal.FourNines,
+		RedealBelow:            p.Redeal.BelowPoints,
+		BarrelAt:               p.Barrel.At,
+	}
+}
+
+// Effective returns Params with the engine's defaults filled in for the
+// omitted options.
+func (p Profile) Effective() engine.GameParams {
+	players := []engine.PlayerID{"P1", "P2"}
+	params := engine.NewGame(p.Params(), players[0], players, nil).Params
+	params.Players = nil
+	return params
+}
@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBuiltinProfiles(t *testing.T) {
+	for name, target := range map[string]int{"PL standard": 1000, "pl-standard": 1000, "KURNIK": 1000, "HOUSE": 500} {
+		p, err := Load(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Validate(); err != nil {
+			t.Errorf("%s: %v", p.Name, err)
+		}
+		if got := p.Effective().MaxGamePoints; got != target {
+			t.Errorf("%s: target %d, want %d", name, got, target)
+		}
+	}
+}
+
+func TestLoadProfileFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "short.json")
+	body := `{"name": "short", "deal": {"handCards": 11, "musiks": 1}, "auction": {"minRaise": 5}}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := p.Effective()
+	if params.HandCards != 11 || params.MusiksCount != 1 || params.MusikSize != 2 ||
+		params.MinBid != 100 || params.MinRaise != 5 || params.MaxGamePoints != 1000 {
+		t.Errorf("params %+v", params)
+	}
+	if p.Params().MinBid != 0 {
+		t.Error("Params fills in defaults")
+	}
+}
+
+func TestParseRejectsBadProfiles(t *testing.T) {
+	for body, want := range map[string]string{
+		`{"name": "x", "deal": {"cards": 10}}`:         `unknown field "cards"`,
+		`{"name": "x", "colour": "red"}`:               `unknown field "colour"`,
+		`{"name": "x", "scoring": {"round": true}}`:    `unknown field "round"`,
+		`{"name": "x", "barrel": {"at": 1000}}`:        "does not lie below the target score",
+		`{"name": "x", "auction": {"maxBid": 90}}`:     "below the opening bid of 100",
+		`{"name": "x", "redeal": {"belowPoints": 40}}`: "only supported below 30",
+		`{"name": "x", "deal": {"handCards": 9}}`:      "deal uses 22 cards",
+		`{"name": "x", "deal": {"musikSize": 1}}`:      "only musiks of 2",
+		`{"name": "x", "auction": {"minRaise": -10}}`:  "minRaise is -10",
+		`["x"]`: "cannot unmarshal",
+	} {
+		_, err := Parse(strings.NewReader(body))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", body, err, want)
+		}
+	}
+}
+
+func TestVariantSections(t *testing.T) {
+	body := `{"name": "v", "auction": {"maxBid": 300, "maxBidWithoutMarriage": 120}, "marriage": {"notOnFirstTrick": true},
+		"scoring": {"roundTo": 5}, "redeal": {"fourNines": true, "belowPoints": 18}, "barrel": {"at": 880}}`
+	p, err := Parse(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := p.Effective()
+	if params.MaxBid != 300 || params.MaxBidWithoutMarriage != 120 || !params.NoMarriageOnFirstTrick ||
+		params.ScoreRounding != 5 || !params.RedealFourNines || params.RedealBelow != 18 || params.BarrelAt != 880 {
+		t.Errorf("params %+v", params)
+	}
+}
@@ -0,0 +1,410 @@
+// Package simulation plays batches of matches between two bots and
+// aggregates statistics about the bidding and play.
+package simulation
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/handrecord"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// Config describes a batch. Zero values get the defaults noted.
+type Config struct {
+	// Bots holds the two bot specs; seats alternate between matches so
+	// neither bot always deals first.
+	Bots [2]string
+	// Matches is the number of matches played (default 100).
+	Matches int
+	// Seed derives every deal and bot seed.
+	Seed int64
+	// Parallel is the number of matches played at once (default GOMAXPROCS).
+	Parallel int
+	// Params are the game rules; Players is ignored.
+	Params engine.GameParams
+	// MaxHands ends a match undecided after that many hands (default 100).
+	MaxHands int
+	// Timeout bounds each bot decision (default 5s); a bot that fails a
+	// decision loses it to a random legal move.
+	Timeout time.Duration
+	// Record keeps the hand histories in Report.Records.
+	Record bool
+}
+
+// Level is the outcome of the contracts played at one bid.
+type Level struct {
+	Bid   int `json:"bid"`
+	Hands int `json:"hands"`
+	Made  int `json:"made"`
+	// SuccessRate is the share of the contracts made.
+	SuccessRate float64 `json:"successRate"`
+}
+
+// Marriage counts the announcements of one suit.
+type Marriage struct {
+	Suit  engine.Suit `json:"suit"`
+	Count int         `json:"count"`
+	// PerHand is the mean number of announcements per hand.
+	PerHand float64 `json:"perHand"`
+}
+
+// FirstPlayer measures the advantage of bidding first, that is of sitting
+// after the dealer: in every hand for the player in that seat, and over a
+// match for the player bidding first in its opening hand.
+type FirstPlayer struct {
+	// DeclarerRate is the share of hands the first bidder declared.
+	DeclarerRate float64 `json:"declarerRate"`
+	// PointsPerHand is the mean score change of the first bidder minus the
+	// mean of the other players.
+	PointsPerHand float64 `json:"pointsPerHand"`
+	// MatchWinRate is the share of decided matches won by the first bidder
+	// of the opening hand.
+	MatchWinRate float64 `json:"matchWinRate"`
+}
+
+// Bot is one bot's record over the batch.
+type Bot struct {
+	Spec string `json:"spec"`
+	Wins int    `json:"wins"`
+	// Points is the mean final score per match.
+	Points float64 `json:"points"`
+}
+
+// Report aggregates a batch.
+type Report struct {
+	Seed    int64 `json:"seed"`
+	Matches int   `json:"matches"`
+	// Undecided counts matches stopped by MaxHands or ended level.
+	Undecided     int     `json:"undecided"`
+	Hands         int     `json:"hands"`
+	HandsPerMatch float64 `json:"handsPerMatch"`
+	// AverageBid is the mean winning bid, the dealer's automatic bid
+	// included.
+	AverageBid float64 `json:"averageBid"`
+	// SuccessRate is the share of all contracts made.
+	SuccessRate float64    `json:"successRate"`
+	Contracts   []Level    `json:"contracts"`
+	Marriages   []Marriage `json:"marriages"`
+	// MarriagesPerHand is the mean number of announcements per hand.
+	MarriagesPerHand float64     `json:"marriagesPerHand"`
+	FirstPlayer      FirstPlayer `json:"firstPlayer"`
+	Bots             []Bot       `json:"bots"`
+	Offences         int         `json:"offences"`
+	// Records holds the hand histories in match order when Config.Record
+	// is set.
+	Records []*handrecord.Record `json:"-"`
+}
+
+// hand is what a Report needs to know about one played hand.
+type hand struct {
+	bid       int
+	made      bool
+	first     bool // the first bidder declared
+	marriages []engine.Suit
+	// edge is the first bidder's score change minus the others' mean.
+	edge float64
+}
+
+// result is one played match.
+type result struct {
+	hands []hand
+	// seats maps seating order to the index of the bot in Config.Bots.
+	seats  [2]int
+	points [2]int
+	// winner is the winning seat, or -1.
+	winner   int
+	records  []*handrecord.Record
+	offences int
+}
+
+// Run plays the batch described by cfg. Zero counts get their defaults;
+// negative ones are errors.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.Matches < 0 || cfg.Parallel < 0 || cfg.MaxHands < 0 || cfg.Timeout < 0 {
+		return nil, fmt.Errorf("negative matches, parallelism, hands or timeout")
+	}
+	if cfg.Matches == 0 {
+		cfg.Matches = 100
+	}
+	if cfg.Parallel == 0 {
+		cfg.Parallel = runtime.GOMAXPROCS(0)
+	}
+	if cfg.MaxHands == 0 {
+		cfg.MaxHands = 100
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	var factories [2]player.PlayerFactory
+	for i, spec := range cfg.Bots {
+		f, err := player.FactoryFor(spec)
+		if err != nil {
+			return nil, err
+		}
+		factories[i] = f
+	}
+
+	// seeds are drawn up front so results do not depend on scheduling
+	seeds := rand.New(rand.NewSource(cfg.Seed))
+	matchSeeds := make([]int64, cfg.Matches)
+	for i := range matchSeeds {
+		matchSeeds[i] = seeds.Int63()
+	}
+	results := make([]result, cfg.Matches)
+	errs := make([]error, cfg.Matches)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = play(ctx, cfg, factories, i, matchSeeds[i])
+			}
+		}()
+	}
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return aggregate(cfg, results), nil
+}
+
+// play plays match n; odd matches swap the seats. Each match has its own
+// runner, so fallback moves do not depend on the other matches.
+func play(ctx context.Context, cfg Config, factories [2]player.PlayerFactory, n int, seed int64) (result, error) {
+	res := result{seats: [2]int{0, 1}, winner: -1}
+	if n%2 == 1 {
+		res.seats = [2]int{1, 0}
+	}
+	rng := rand.New(rand.NewSource(seed))
+	var bots [2]player.Player
+	for s, b := range res.seats {
+		bots[s] = factories[b](rng.Int63())
+	}
+	defer func() {
+		for _, b := range bots {
+			if c, ok := b.(interface{ Close() error }); ok {
+				c.Close()
+			}
+		}
+	}()
+	runner := &match.Runner{Timeout: cfg.Timeout, Fallback: match.FallbackRandom, Rand: rand.New(rand.NewSource(rng.Int63()))}
+	if cfg.Record {
+		var rec *handrecord.Record
+		runner.OnDealt = func(g *engine.GameState) {
+			rec = handrecord.New(g)
+			res.records = append(res.records, rec)
+		}
+		runner.OnScored = func(g *engine.GameState) { rec.Update(g) }
+	}
+	players := match.PlayerIDs(bots[0], bots[1])
+	seated := map[engine.PlayerID]player.Player{players[0]: bots[0], players[1]: bots[1]}
+	game, err := runner.PlayGame(ctx, cfg.Params, players, seated, rng, cfg.MaxHands)
+	res.offences = len(runner.Offences())
+	if err != nil {
+		return res, fmt.Errorf("match %d (seed %d): %w", n+1, seed, err)
+	}
+	for _, h := range game.Hands {
+		res.hands = append(res.hands, summarise(h, players))
+	}
+	for s, p := range players {
+		res.points[s] = game.Cumulative[p]
+		if p == game.Winner {
+			res.winner = s
+		}
+	}
+	return res, nil
+}
+
+// summarise describes a scored hand between players.
+func summarise(r match.HandResult, players []engine.PlayerID) hand {
+	first := firstBidder(players, r.Dealer)
+	h := hand{bid: r.Bid, made: r.Made, first: r.Declarer == first, marriages: r.Marriages}
+	others := 0.0
+	for _, p := range players {
+		if p != first {
+			others += float64(r.Delta[p])
+		}
+	}
+	h.edge = float64(r.Delta[first]) - others/float64(len(players)-1)
+	return h
+}
+
+// firstBidder returns the player after dealer, who bids first.
+func firstBidder(players []engine.PlayerID, dealer engine.PlayerID) engine.PlayerID {
+	for i, p := range players {
+		if p == dealer {
+			return players[(i+1)%len(players)]
+		}
+	}
+	return ""
+}
+
+func aggregate(cfg Config, results []result) *Report {
+	rep := &Report{Seed: cfg.Seed, Matches: len(results)}
+	levels := map[int]*Level{}
+	marriages := map[engine.Suit]int{}
+	var bids, made, firstDeclared, decided, firstWon int
+	var edge float64
+	bots := []Bot{{Spec: cfg.Bots[0]}, {Spec: cfg.Bots[1]}}
+	for _, res := range results {
+		rep.Records = append(rep.Records, res.records...)
+		rep.Offences += res.offences
+		for _, h := range res.hands {
+			rep.Hands++
+			bids += h.bid
+			l := levels[h.bid]
+			if l == nil {
+				l = &Level{Bid: h.bid}
+				levels[h.bid] = l
+			}
+			l.Hands++
+			if h.made {
+				l.Made++
+				made++
+			}
+			if h.first {
+				firstDeclared++
+			}
+			edge += h.edge
+			for _, s := range h.marriages {
+				marriages[s]++
+			}
+		}
+		for s, b := range res.seats {
+			bots[b].Points += float64(res.points[s])
+		}
+		if res.winner < 0 {
+			rep.Undecided++
+			continue
+		}
+		bots[res.seats[res.winner]].Wins++
+		decided++
+		// the first seat deals the opening hand, so the second bids first
+		if res.winner == 1 {
+			firstWon++
+		}
+	}
+	for i := range bots {
+		bots[i].Points = ratio(bots[i].Points, len(results))
+	}
+	rep.Bots = bots
+	rep.HandsPerMatch = ratio(float64(rep.Hands), len(results))
+	rep.AverageBid = ratio(float64(bids), rep.Hands)
+	rep.SuccessRate = ratio(float64(made), rep.Hands)
+	for _, l := range levels {
+		l.SuccessRate = ratio(float64(l.Made), l.Hands)
+		rep.Contracts = append(rep.Contracts, *l)
+	}
+	sort.Slice(rep.Contracts, func(i, j int) bool { return rep.Contracts[i].Bid < rep.Contracts[j].Bid })
+	total := 0
+	for _, s := range engine.Suits {
+		rep.Marriages = append(rep.Marriages, Marriage{Suit: s, Count: marriages[s], PerHand: ratio(float64(marriages[s]), rep.Hands)})
+		total += marriages[s]
+	}
+	rep.MarriagesPerHand = ratio(float64(total), rep.Hands)
+	rep.FirstPlayer = FirstPlayer{
+		DeclarerRate:  ratio(float64(firstDeclared), rep.Hands),
+		PointsPerHand: ratio(edge, rep.Hands),
+		MatchWinRate:  ratio(float64(firstWon), decided),
+	}
+	return rep
+}
+
+func ratio(x float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return x / float64(n)
+}
+
+// WriteText writes the report as a table for people.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%d matches, %d hands (%.1f per match), seed %d\n", r.Matches, r.Hands, r.HandsPerMatch, r.Seed)
+	if r.Undecided > 0 {
+		fmt.Fprintf(tw, "%d matches undecided\n", r.Undecided)
+	}
+	fmt.Fprintln(tw, "\nbot\twins\tpoints/match")
+	for _, b := range r.Bots {
+		fmt.Fprintf(tw, "%s\t%d\t%.0f\n", b.Spec, b.Wins, b.Points)
+	}
+	fmt.Fprintf(tw, "\naverage bid %.1f, %.1f%% of contracts made\n", r.AverageBid, 100*r.SuccessRate)
+	fmt.Fprintln(tw, "bid\thands\tmade\tsuccess")
+	for _, l := range r.Contracts {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%.1f%%\n", l.Bid, l.Hands, l.Made, 100*l.SuccessRate)
+	}
+	fmt.Fprintf(tw, "\n%.2f marriages per hand\n", r.MarriagesPerHand)
+	fmt.Fprintln(tw, "suit\tcount\tper hand")
+	for _, m := range r.Marriages {
+		fmt.Fprintf(tw, "%s\t%d\t%.3f\n", m.Suit.Letter(), m.Count, m.PerHand)
+	}
+	fmt.Fprintf(tw, "\nfirst bidder: declares %.1f%% of hands, %+.1f points per hand, wins %.1f%% of decided matches\n",
+		100*r.FirstPlayer.DeclarerRate, r.FirstPlayer.PointsPerHand, 100*r.FirstPlayer.MatchWinRate)
+	if r.Offences > 0 {
+		fmt.Fprintf(tw, "%d bot offences\n", r.Offences)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes the report as metric,key,value rows; key qualifies
+// metrics broken down by bid, suit or bot.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	rows := [][]string{
+		{"metric", "key", "value"},
+		{"seed", "", strconv.FormatInt(r.Seed, 10)},
+		{"matches", "", strconv.Itoa(r.Matches)},
+		{"undecided", "", strconv.Itoa(r.Undecided)},
+		{"hands", "", strconv.Itoa(r.Hands)},
+		{"hands_per_match", "", f(r.HandsPerMatch)},
+		{"average_bid", "", f(r.AverageBid)},
+		{"success_rate", "", f(r.SuccessRate)},
+	}
+	for _, l := range r.Contracts {
+		bid := strconv.Itoa(l.Bid)
+		rows = append(rows,
+			[]string{"contracts", bid, strconv.Itoa(l.Hands)},
+			[]string{"contracts_made", bid, strconv.Itoa(l.Made)},
+			[]string{"success_rate", bid, f(l.SuccessRate)})
+	}
+	rows = append(rows, []string{"marriages_per_hand", "", f(r.MarriagesPerHand)})
+	for _, m := range r.Marriages {
+		rows = append(rows,
+			[]string{"marriages", m.Suit.Letter(), strconv.Itoa(m.Count)},
+			[]string{"marriages_per_hand", m.Suit.Letter(), f(m.PerHand)})
+	}
+	rows = append(rows,
+		[]string{"first_bidder_declarer_rate", "", f(r.FirstPlayer.DeclarerRate)},
+		[]string{"first_bidder_points_per_hand", "", f(r.FirstPlayer.PointsPerHand)},
+		[]string{"first_bidder_match_win_rate", "", f(r.FirstPlayer.MatchWinRate)})
+	for _, b := range r.Bots {
+		rows = append(rows,
+			[]string{"wins", b.Spec, strconv.Itoa(b.Wins)},
+			[]string{"points_per_match", b.Spec, f(b.Points)})
+	}
+	rows = append(rows, []string{"offences", "", strconv.Itoa(r.Offences)})
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRunAggregatesMatches(t *testing.T) {
+	cfg := Config{Bots: [2]string{"random", "heuristic:easy"}, Matches: 6, Seed: 9, Parallel: 3, Record: true}
+	rep, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Matches != 6 || rep.Hands == 0 || len(rep.Records) != rep.Hands {
+		t.Fatalf("%d matches, %d hands, %d records", rep.Matches, rep.Hands, len(rep.Records))
+	}
+	hands := 0
+	for _, l := range rep.Contracts {
+		hands += l.Hands
+		if l.Made > l.Hands || l.Bid < 100 {
+			t.Errorf("level %+v", l)
+		}
+	}
+	if hands != rep.Hands {
+		t.Errorf("contracts cover %d of %d hands", hands, rep.Hands)
+	}
+	if wins := rep.Bots[0].Wins + rep.Bots[1].Wins; wins+rep.Undecided != rep.Matches {
+		t.Errorf("%d wins and %d undecided in %d matches", wins, rep.Undecided, rep.Matches)
+	}
+	if rep.Bots[1].Wins <= rep.Bots[0].Wins {
+		t.Errorf("heuristic won %d, random %d", rep.Bots[1].Wins, rep.Bots[0].Wins)
+	}
+
+	// scheduling must not change the results
+	cfg.Parallel = 1
+	again, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := json.Marshal(rep)
+	b, _ := json.Marshal(again)
+	if string(a) != string(b) {
+		t.Errorf("parallel run differs:\n%s\n%s", a, b)
+	}
+
+	for _, bad := range []Config{{Matches: -1}, {Parallel: -2}} {
+		bad.Bots = cfg.Bots
+		if _, err := Run(context.Background(), bad); err == nil {
+			t.Errorf("ran %+v", bad)
+		}
+	}
+
+	var sb strings.Builder
+	if err := rep.WriteCSV(&sb); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(rows[0], ",") != "metric,key,value" || len(rows) < 20 {
+		t.Errorf("csv starts %v, %d rows", rows[0], len(rows))
+	}
+}
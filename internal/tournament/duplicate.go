@@ -0,0 +1,124 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// Pairing summarises the duplicate deals between two entrants. Margin is the
+// mean per-deal point difference in favour of Players[0].
+type Pairing struct {
+	Players               [2]string
+	Deals                 int
+	Margin                float64
+	MarginLow, MarginHigh float64
+}
+
+// playDuplicate plays the deal drawn from g.Seed twice: the second time the
+// entrants swap seats, so each gets the cards and dealer role the other had.
+// Every play starts from zero with fresh bots.
+func (t *tournament) playDuplicate(ctx context.Context, g *Game) error {
+	g.Duplicate = true
+	ids := []engine.PlayerID{engine.PlayerID(g.Players[0]), engine.PlayerID(g.Players[1])}
+	// deal once to skip the decks the rules redeal, then replay the cards
+	probe := engine.NewGame(t.cfg.Params, ids[0], ids, nil)
+	if err := match.Deal(probe, rand.New(rand.NewSource(g.Seed))); err != nil {
+		return err
+	}
+	deck := append(append([]engine.Card{}, probe.Deal.Hands[ids[0]]...), probe.Deal.Hands[ids[1]]...)
+	for _, m := range probe.Deal.Musiks {
+		deck = append(deck, m...)
+	}
+	for _, order := range [][]engine.PlayerID{ids, {ids[1], ids[0]}} {
+		bots := t.newBots(order, g.Seed)
+		hand, err := t.playDeal(ctx, order, deck, bots)
+		closeBots(bots)
+		var forfeit *match.ForfeitError
+		switch {
+		case errors.As(err, &forfeit):
+			g.forfeit(forfeit.Offence)
+			return nil
+		case err != nil:
+			return fmt.Errorf("%s vs %s (seed %d): %w", g.Players[0], g.Players[1], g.Seed, err)
+		}
+		g.Hands = append(g.Hands, *hand)
+		g.Points[0] += hand.Delta[ids[0]]
+		g.Points[1] += hand.Delta[ids[1]]
+	}
+	switch {
+	case g.Points[0] > g.Points[1]:
+		g.Winner = g.Players[0]
+	case g.Points[1] > g.Points[0]:
+		g.Winner = g.Players[1]
+	}
+	return nil
+}
+
+// playDeal plays one hand of deck with order[0] dealing.
+func (t *tournament) playDeal(ctx context.Context, order []engine.PlayerID, deck []engine.Card, bots map[engine.PlayerID]player.Player) (*match.HandResult, error) {
+	gs := engine.NewGame(t.cfg.Params, order[0], order, nil)
+	if err := match.DealDeck(gs, deck); err != nil {
+		return nil, err
+	}
+	if err := t.runner.PlayHand(ctx, gs, bots); err != nil {
+		return nil, err
+	}
+	hr := match.Result(gs, nil)
+	return &hr, nil
+}
+
+// computePairings summarises duplicate games per pair in order of first
+// appearance.
+func computePairings(games []Game) []Pairing {
+	var out []Pairing
+	margins := map[[2]string][]float64{}
+	for _, g := range games {
+		if !g.Duplicate || g.Forfeit != "" {
+			continue
+		}
+		key, m := g.Players, float64(g.Points[0]-g.Points[1])
+		if _, ok := margins[key]; !ok {
+			if _, ok := margins[[2]string{key[1], key[0]}]; ok {
+				key, m = [2]string{key[1], key[0]}, -m
+			} else {
+				out = append(out, Pairing{Players: key})
+			}
+		}
+		margins[key] = append(margins[key], m)
+	}
+	for i := range out {
+		ms := margins[out[i].Players]
+		out[i].Deals = len(ms)
+		out[i].Margin, out[i].MarginLow, out[i].MarginHigh = meanCI(ms)
+	}
+	return out
+}
+
+// meanCI returns the mean of xs and its approximate 95% confidence interval;
+// the interval is zero with fewer than two samples.
+func meanCI(xs []float64) (mean, low, high float64) {
+	if len(xs) == 0 {
+		return 0, 0, 0
+	}
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0, 0
+	}
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= float64(len(xs) - 1)
+	margin := 1.96 * math.Sqrt(variance/float64(len(xs)))
+	return mean, mean - margin, mean + margin
+}
@@ -0,0 +1,92 @@
+package tournament
+
+import "math"
+
+// eloScale converts natural-log strength to Elo points.
+var eloScale = 400 / math.Ln10
+
+// rate fits a Bradley-Terry model to the game results and stores Elo
+// ratings, centred on 1500, with approximate 95% confidence intervals.
+// Every pair that met gets one virtual draw so unbeaten or winless entrants
+// keep finite ratings. Intervals use each entrant's Fisher information with
+// the other ratings held fixed; they stay zero for entrants without games.
+func rate(out []Standing, games []Game, idx map[string]int) {
+	n := len(out)
+	played := make([][]float64, n) // games between i and j
+	wins := make([]float64, n)
+	for i := range played {
+		played[i] = make([]float64, n)
+	}
+	for _, g := range games {
+		a, b := idx[g.Players[0]], idx[g.Players[1]]
+		played[a][b]++
+		played[b][a]++
+		switch g.Winner {
+		case "":
+			wins[a] += 0.5
+			wins[b] += 0.5
+		case g.Players[0]:
+			wins[a]++
+		default:
+			wins[b]++
+		}
+	}
+	for i := range played {
+		for j := range played[i] {
+			if i != j && played[i][j] > 0 {
+				played[i][j]++
+				wins[i] += 0.5
+			}
+		}
+	}
+
+	// minorisation-maximisation updates (Hunter 2004)
+	gamma := make([]float64, n)
+	for i := range gamma {
+		gamma[i] = 1
+	}
+	for iter := 0; iter < 1000; iter++ {
+		change := 0.0
+		for i := range gamma {
+			denom := 0.0
+			for j := range gamma {
+				if played[i][j] > 0 {
+					denom += played[i][j] / (gamma[i] + gamma[j])
+				}
+			}
+			if denom == 0 {
+				continue
+			}
+			next := wins[i] / denom
+			change = math.Max(change, math.Abs(math.Log(next/gamma[i])))
+			gamma[i] = next
+		}
+		mean := 0.0
+		for _, g := range gamma {
+			mean += math.Log(g)
+		}
+		mean /= float64(n)
+		for i := range gamma {
+			gamma[i] /= math.Exp(mean)
+		}
+		if change < 1e-9 {
+			break
+		}
+	}
+
+	for i := range out {
+		info := 0.0
+		for j := range gamma {
+			if played[i][j] > 0 {
+				p := gamma[i] / (gamma[i] + gamma[j])
+				info += played[i][j] * p * (1 - p)
+			}
+		}
+		out[i].Rating = 1500 + eloScale*math.Log(gamma[i])
+		if info == 0 {
+			continue
+		}
+		margin := 1.96 * eloScale / math.Sqrt(info)
+		out[i].RatingLow, out[i].RatingHigh = out[i].Rating-margin, out[i].Rating+margin
+	}
+}
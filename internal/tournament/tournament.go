@@ -0,0 +1,401 @@
+// Package tournament plays bots against each other and rates them.
+package tournament
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// Format selects how entrants are paired.
+type Format string
+
+const (
+	RoundRobin Format = "round-robin"
+	Swiss      Format = "swiss"
+)
+
+// Entrant is a named bot type; New is called for every game.
+type Entrant struct {
+	Name string
+	New  player.PlayerFactory
+}
+
+// Config describes a tournament. Zero values get the defaults noted.
+type Config struct {
+	Entrants []Entrant
+	Format   Format // default RoundRobin
+	// Games is the number of games per pairing (default 2); seats alternate.
+	Games int
+	// Rounds is the number of Swiss rounds (default: enough to rank everyone).
+	Rounds int
+	// Seed derives every deal of the tournament.
+	Seed int64
+	// Parallel is the number of games played at once (default GOMAXPROCS).
+	Parallel int
+	// Params are the game rules; Players is ignored.
+	Params engine.GameParams
+	// Timeout bounds each bot decision (default 10s).
+	Timeout time.Duration
+	// MaxHands ends a game as a draw after that many hands (default 100).
+	MaxHands int
+	// Duplicate turns every game into one deal played twice with seats
+	// swapped; the entrant with more points over both plays wins it.
+	Duplicate bool
+}
+
+// Game is the result of one game between two entrants.
+type Game struct {
+	Round   int
+	Seed    int64
+	Players [2]string
+	// Winner is empty for a draw.
+	Winner string
+	Points [2]int
+	Hands  []match.HandResult
+	// Forfeit describes the offence that ended the game early.
+	Forfeit string `json:",omitempty"`
+	// Duplicate marks a duplicate deal; Points then sum both plays and Hands
+	// holds the two hands.
+	Duplicate bool `json:",omitempty"`
+}
+
+// Report holds every game and the final standings, best first.
+type Report struct {
+	Format    Format
+	Seed      int64
+	Games     []Game
+	Standings []Standing
+	// Pairings summarises duplicate deals per pair of entrants.
+	Pairings []Pairing `json:",omitempty"`
+	Offences []match.Offence
+}
+
+// Run plays the tournament described by cfg.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if len(cfg.Entrants) < 2 {
+		return nil, errors.New("tournament needs at least two entrants")
+	}
+	seen := map[string]bool{}
+	for _, e := range cfg.Entrants {
+		if seen[e.Name] {
+			return nil, fmt.Errorf("duplicate entrant %q", e.Name)
+		}
+		seen[e.Name] = true
+	}
+	if cfg.Format == "" {
+		cfg.Format = RoundRobin
+	}
+	if cfg.Games == 0 {
+		cfg.Games = 2
+	}
+	if cfg.Parallel == 0 {
+		cfg.Parallel = runtime.GOMAXPROCS(0)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxHands == 0 {
+		cfg.MaxHands = 100
+	}
+	if cfg.Rounds == 0 {
+		for n := 1; n < len(cfg.Entrants); n *= 2 {
+			cfg.Rounds++
+		}
+	}
+
+	t := &tournament{
+		cfg:     cfg,
+		seeds:   rand.New(rand.NewSource(cfg.Seed)),
+		runner:  &match.Runner{Timeout: cfg.Timeout, Fallback: match.FallbackForfeit},
+		entrant: map[string]Entrant{},
+	}
+	for _, e := range cfg.Entrants {
+		t.entrant[e.Name] = e
+	}
+	var games []Game
+	switch cfg.Format {
+	case RoundRobin:
+		var pairs [][2]string
+		for i, a := range cfg.Entrants {
+			for _, b := range cfg.Entrants[i+1:] {
+				pairs = append(pairs, [2]string{a.Name, b.Name})
+			}
+		}
+		played, err := t.playRound(ctx, 1, pairs)
+		if err != nil {
+			return nil, err
+		}
+		games = played
+	case Swiss:
+		met := map[[2]string]bool{}
+		for round := 1; round <= cfg.Rounds; round++ {
+			pairs := swissPairs(computeStandings(cfg.Entrants, games), met)
+			played, err := t.playRound(ctx, round, pairs)
+			if err != nil {
+				return nil, err
+			}
+			games = append(games, played...)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", cfg.Format)
+	}
+	return &Report{
+		Format:    cfg.Format,
+		Seed:      cfg.Seed,
+		Games:     games,
+		Standings: computeStandings(cfg.Entrants, games),
+		Pairings:  computePairings(games),
+		Offences:  t.runner.Offences(),
+	}, nil
+}
+
+type tournament struct {
+	cfg     Config
+	seeds   *rand.Rand
+	runner  *match.Runner
+	entrant map[string]Entrant
+}
+
+// playRound plays cfg.Games games for every pair, alternating who deals first.
+// Seeds are drawn up front so results do not depend on scheduling.
+func (t *tournament) playRound(ctx context.Context, round int, pairs [][2]string) ([]Game, error) {
+	var games []Game
+	for _, pair := range pairs {
+		for i := 0; i < t.cfg.Games; i++ {
+			g := Game{Round: round, Seed: t.seeds.Int63(), Players: pair}
+			if i%2 == 1 {
+				g.Players = [2]string{pair[1], pair[0]}
+			}
+			games = append(games, g)
+		}
+	}
+	errs := make([]error, len(games))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < t.cfg.Parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = t.play(ctx, &games[i])
+			}
+		}()
+	}
+	for i := range games {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// play fills in the outcome of g. A forfeit loses the game.
+func (t *tournament) play(ctx context.Context, g *Game) error {
+	if t.cfg.Duplicate {
+		return t.playDuplicate(ctx, g)
+	}
+	ids := []engine.PlayerID{engine.PlayerID(g.Players[0]), engine.PlayerID(g.Players[1])}
+	bots := t.newBots(ids, g.Seed)
+	defer closeBots(bots)
+	res, err := t.runner.PlayGame(ctx, t.cfg.Params, ids, bots, rand.New(rand.NewSource(g.Seed)), t.cfg.MaxHands)
+	var forfeit *match.ForfeitError
+	switch {
+	case errors.As(err, &forfeit):
+		g.forfeit(forfeit.Offence)
+		return nil
+	case err != nil:
+		return fmt.Errorf("%s vs %s (seed %d): %w", g.Players[0], g.Players[1], g.Seed, err)
+	}
+	g.Winner = string(res.Winner)
+	g.Points = [2]int{res.Cumulative[ids[0]], res.Cumulative[ids[1]]}
+	g.Hands = res.Hands
+	return nil
+}
+
+// newBots creates fresh bots for the entrants named by ids. Each bot's seed
+// depends only on the game seed and its entrant, never on scheduling.
+func (t *tournament) newBots(ids []engine.PlayerID, seed int64) map[engine.PlayerID]player.Player {
+	bots := map[engine.PlayerID]player.Player{}
+	for _, id := range ids {
+		h := fnv.New64a()
+		h.Write([]byte(id))
+		bots[id] = t.entrant[string(id)].New(seed ^ int64(h.Sum64()))
+	}
+	return bots
+}
+
+// forfeit awards g to the opponent of the offender.
+func (g *Game) forfeit(o match.Offence) {
+	g.Forfeit = o.String()
+	g.Winner = g.Players[0]
+	if string(o.Player) == g.Players[0] {
+		g.Winner = g.Players[1]
+	}
+}
+
+// closeBots releases bots that hold resources, such as bot processes.
+func closeBots(bots map[engine.PlayerID]player.Player) {
+	for _, b := range bots {
+		if c, ok := b.(interface{ Close() error }); ok {
+			c.Close()
+		}
+	}
+}
+
+// swissPairs pairs entrants with similar scores who have not met yet. With an
+// odd field the lowest-ranked entrant without a bye yet sits out the round.
+func swissPairs(standings []Standing, met map[[2]string]bool) [][2]string {
+	order := make([]string, 0, len(standings))
+	for _, s := range standings {
+		order = append(order, s.Name)
+	}
+	if len(order)%2 == 1 {
+		bye := len(order) - 1
+		for i := bye; i >= 0; i-- {
+			if !met[[2]string{order[i], ""}] {
+				bye = i
+				break
+			}
+		}
+		met[[2]string{order[bye], ""}] = true
+		order = append(order[:bye], order[bye+1:]...)
+	}
+	var pairs [][2]string
+	used := map[string]bool{}
+	for i, a := range order {
+		if used[a] {
+			continue
+		}
+		partner := ""
+		for _, b := range order[i+1:] {
+			if used[b] {
+				continue
+			}
+			if partner == "" {
+				partner = b
+			}
+			if !met[[2]string{a, b}] {
+				partner = b
+				break
+			}
+		}
+		if partner == "" {
+			break
+		}
+		used[a], used[partner] = true, true
+		met[[2]string{a, partner}], met[[2]string{partner, a}] = true, true
+		pairs = append(pairs, [2]string{a, partner})
+	}
+	return pairs
+}
+
+// Standing aggregates one entrant's results.
+type Standing struct {
+	Name                  string
+	Games                 int
+	Wins, Draws, Losses   int
+	Hands                 int
+	Points                int // sum of cumulative score changes
+	Contracts             int // hands played as declarer
+	ContractsMade         int
+	Rating                float64
+	RatingLow, RatingHigh float64
+	// Margin is the mean per-deal point difference over duplicate deals.
+	Margin                float64
+	MarginLow, MarginHigh float64
+}
+
+// Score is wins plus half the draws.
+func (s Standing) Score() float64 { return float64(s.Wins) + float64(s.Draws)/2 }
+
+// WinRate is the score per game.
+func (s Standing) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return s.Score() / float64(s.Games)
+}
+
+// AvgPoints is the mean score change per hand.
+func (s Standing) AvgPoints() float64 {
+	if s.Hands == 0 {
+		return 0
+	}
+	return float64(s.Points) / float64(s.Hands)
+}
+
+// ContractRate is the share of contracts made as declarer.
+func (s Standing) ContractRate() float64 {
+	if s.Contracts == 0 {
+		return 0
+	}
+	return float64(s.ContractsMade) / float64(s.Contracts)
+}
+
+// computeStandings tallies games and rates the entrants, best first. Ties
+// keep entrant order.
+func computeStandings(entrants []Entrant, games []Game) []Standing {
+	idx := map[string]int{}
+	out := make([]Standing, len(entrants))
+	for i, e := range entrants {
+		idx[e.Name] = i
+		out[i].Name = e.Name
+	}
+	margins := make([][]float64, len(entrants))
+	for _, g := range games {
+		// a forfeited deal says nothing about the margin, as in computePairings
+		if g.Duplicate && g.Forfeit == "" {
+			m := float64(g.Points[0] - g.Points[1])
+			margins[idx[g.Players[0]]] = append(margins[idx[g.Players[0]]], m)
+			margins[idx[g.Players[1]]] = append(margins[idx[g.Players[1]]], -m)
+		}
+		for _, name := range g.Players {
+			s := &out[idx[name]]
+			s.Games++
+			switch g.Winner {
+			case "":
+				s.Draws++
+			case name:
+				s.Wins++
+			default:
+				s.Losses++
+			}
+			id := engine.PlayerID(name)
+			for _, h := range g.Hands {
+				s.Hands++
+				s.Points += h.Delta[id]
+				if h.Declarer == id {
+					s.Contracts++
+					if h.Made {
+						s.ContractsMade++
+					}
+				}
+			}
+		}
+	}
+	for i := range out {
+		out[i].Margin, out[i].MarginLow, out[i].MarginHigh = meanCI(margins[i])
+	}
+	rate(out, games, idx)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score() != out[j].Score() {
+			return out[i].Score() > out[j].Score()
+		}
+		return out[i].Rating > out[j].Rating
+	})
+	return out
+}
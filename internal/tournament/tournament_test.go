@@ -0,0 +1,158 @@
+package tournament
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+func heuristic(int64) player.Player { return player.NewHeuristicBot() }
+
+func TestRatingsFollowResults(t *testing.T) {
+	entrants := []Entrant{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	var games []Game
+	add := func(x, y, winner string, n int) {
+		for i := 0; i < n; i++ {
+			games = append(games, Game{Players: [2]string{x, y}, Winner: winner})
+		}
+	}
+	add("a", "b", "a", 8)
+	add("a", "b", "b", 2)
+	add("b", "c", "b", 8)
+	add("b", "c", "c", 2)
+	add("a", "c", "a", 10)
+
+	st := computeStandings(entrants, games)
+	if st[0].Name != "a" || st[1].Name != "b" || st[2].Name != "c" {
+		t.Fatalf("order = %s %s %s, want a b c", st[0].Name, st[1].Name, st[2].Name)
+	}
+	mean := (st[0].Rating + st[1].Rating + st[2].Rating) / 3
+	if mean < 1499.9 || mean > 1500.1 {
+		t.Fatalf("mean rating = %.1f, want 1500", mean)
+	}
+	for _, s := range st {
+		if !(s.RatingLow < s.Rating && s.Rating < s.RatingHigh) {
+			t.Fatalf("%s: rating %.0f outside %.0f..%.0f", s.Name, s.Rating, s.RatingLow, s.RatingHigh)
+		}
+	}
+	// an unbeaten record still gets a finite rating
+	if st[0].RatingHigh > 3000 {
+		t.Fatalf("unbounded rating for a: %.0f", st[0].RatingHigh)
+	}
+}
+
+func TestSwissAvoidsRematchesAndRotatesByes(t *testing.T) {
+	entrants := []Entrant{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	met := map[[2]string]bool{}
+	byes := map[string]int{}
+	for round := 0; round < 3; round++ {
+		pairs := swissPairs(computeStandings(entrants, nil), met)
+		if len(pairs) != 1 {
+			t.Fatalf("round %d: %d pairs, want 1", round, len(pairs))
+		}
+		for _, e := range entrants {
+			if e.Name != pairs[0][0] && e.Name != pairs[0][1] {
+				byes[e.Name]++
+			}
+		}
+	}
+	for _, e := range entrants {
+		if byes[e.Name] != 1 {
+			t.Fatalf("byes = %v, want one each", byes)
+		}
+	}
+}
+
+func TestRunRoundRobin(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Entrants: []Entrant{
+			{Name: "random", New: player.NewRandomBot},
+			{Name: "heuristic", New: heuristic},
+			{Name: "heuristic#2", New: heuristic},
+		},
+		Games: 2,
+		Seed:  3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Games) != 6 {
+		t.Fatalf("games = %d, want 6", len(report.Games))
+	}
+	for _, s := range report.Standings {
+		if s.Games != 4 || s.Wins+s.Draws+s.Losses != 4 {
+			t.Fatalf("%s: %+v", s.Name, s)
+		}
+	}
+	if report.Standings[2].Name != "random" {
+		t.Fatalf("random bot ranked %q last, want random", report.Standings[2].Name)
+	}
+}
+
+func TestDuplicateCancelsCardLuck(t *testing.T) {
+	// HeuristicBot is deterministic, so a mirror match must come out level
+	// on every duplicate deal.
+	report, err := Run(context.Background(), Config{
+		Entrants: []Entrant{
+			{Name: "heuristic", New: heuristic},
+			{Name: "heuristic#2", New: heuristic},
+		},
+		Games:     6,
+		Seed:      5,
+		Duplicate: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Pairings) != 1 || report.Pairings[0].Deals != 6 {
+		t.Fatalf("pairings = %+v, want one with 6 deals", report.Pairings)
+	}
+	for _, g := range report.Games {
+		if len(g.Hands) != 2 || g.Points[0] != g.Points[1] || g.Winner != "" {
+			t.Fatalf("seed %d: hands %d, points %v, winner %q", g.Seed, len(g.Hands), g.Points, g.Winner)
+		}
+	}
+	if p := report.Pairings[0]; p.Margin != 0 {
+		t.Fatalf("margin = %v, want 0", p.Margin)
+	}
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	run := func() *Report {
+		report, err := Run(context.Background(), Config{
+			Entrants: []Entrant{
+				{Name: "random", New: player.NewRandomBot},
+				{Name: "random#2", New: player.NewRandomBot},
+				{Name: "heuristic", New: heuristic},
+			},
+			Games:    4,
+			Seed:     11,
+			Parallel: 4,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	a, b := run(), run()
+	for i := range a.Games {
+		if a.Games[i].Winner != b.Games[i].Winner || a.Games[i].Points != b.Games[i].Points || len(a.Games[i].Hands) != len(b.Games[i].Hands) {
+			t.Fatalf("game %d differs: %+v vs %+v", i, a.Games[i].Points, b.Games[i].Points)
+		}
+	}
+}
+
+func TestForfeitsLeaveDuplicateMargins(t *testing.T) {
+	entrants := []Entrant{{Name: "a"}, {Name: "b"}}
+	games := []Game{
+		{Players: [2]string{"a", "b"}, Points: [2]int{40, 0}, Winner: "a", Duplicate: true},
+		{Players: [2]string{"a", "b"}, Points: [2]int{20, 0}, Winner: "a", Duplicate: true},
+		{Players: [2]string{"a", "b"}, Points: [2]int{0, 1000}, Winner: "b", Duplicate: true, Forfeit: "a timed out"},
+	}
+	for _, s := range computeStandings(entrants, games) {
+		if want := map[string]float64{"a": 30, "b": -30}[s.Name]; s.Margin != want {
+			t.Errorf("%s: margin %v, want %v", s.Name, s.Margin, want)
+		}
+	}
+}
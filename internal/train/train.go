@@ -0,0 +1,286 @@
+// Package train fits card-play networks for player.NeuralBot on self-play.
+//
+// Training starts by imitating HeuristicBot, which gives the network sound
+// play quickly, then improves it with actor-critic self-play: both seats
+// sample from the current policy and every decision is reinforced by the
+// hand's outcome minus the network's own value estimate.
+package train
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/nn"
+	"github.com/ZygmuntJakub/1000/internal/player"
+	"github.com/ZygmuntJakub/1000/internal/tournament"
+)
+
+// Config describes a training run. Zero values get the defaults noted.
+type Config struct {
+	// Hidden lists the hidden layer widths (default 128, 64).
+	Hidden []int
+	// ImitationHands is the number of heuristic hands to imitate (default 20000).
+	ImitationHands int
+	// SelfPlayHands is the number of self-play hands (default 10000).
+	SelfPlayHands int
+	// BatchHands is the number of hands per optimiser step (default 16).
+	BatchHands int
+	// LearningRate for Adam (default 1e-3).
+	LearningRate float64
+	// ValueWeight scales the value loss (default 0.5).
+	ValueWeight float64
+	// Entropy is the entropy bonus during self-play (default 0.01).
+	Entropy float64
+	// EvalEvery evaluates against HeuristicBot after that many hands of each
+	// phase; zero disables it.
+	EvalEvery int
+	// EvalDeals is the number of duplicate deals per evaluation (default 100).
+	EvalDeals int
+	Seed      int64
+	// Net continues training an existing network instead of a new one.
+	Net *nn.MLP
+}
+
+// sample is one card decision seen during training.
+type sample struct {
+	x      []float64
+	legal  []int
+	action int
+	seat   engine.PlayerID
+	// reward is filled in once the hand is scored.
+	reward float64
+}
+
+// Train runs imitation, then self-play, and returns the trained network.
+// Progress is written to log.
+func Train(ctx context.Context, cfg Config, log io.Writer) (*nn.MLP, error) {
+	if cfg.Hidden == nil {
+		cfg.Hidden = []int{128, 64}
+	}
+	if cfg.ImitationHands == 0 {
+		cfg.ImitationHands = 20000
+	}
+	if cfg.SelfPlayHands == 0 {
+		cfg.SelfPlayHands = 10000
+	}
+	if cfg.BatchHands == 0 {
+		cfg.BatchHands = 16
+	}
+	if cfg.LearningRate == 0 {
+		cfg.LearningRate = 1e-3
+	}
+	if cfg.ValueWeight == 0 {
+		cfg.ValueWeight = 0.5
+	}
+	if cfg.Entropy == 0 {
+		cfg.Entropy = 0.01
+	}
+	if cfg.EvalDeals == 0 {
+		cfg.EvalDeals = 100
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	net := cfg.Net
+	if net == nil {
+		sizes := append(append([]int{player.FeatureSize}, cfg.Hidden...), player.PolicySize)
+		net = nn.New(rng, sizes...)
+	}
+	t := &trainer{cfg: cfg, net: net, rng: rng, opt: nn.NewAdam(cfg.LearningRate), grads: net.NewGrads()}
+
+	phases := []struct {
+		name    string
+		hands   int
+		imitate bool
+	}{
+		{"imitation", cfg.ImitationHands, true},
+		{"self-play", cfg.SelfPlayHands, false},
+	}
+	for _, ph := range phases {
+		for done := 0; done < ph.hands; {
+			if err := ctx.Err(); err != nil {
+				return net, err
+			}
+			n := min(cfg.BatchHands, ph.hands-done)
+			var batch []sample
+			for range n {
+				s, err := t.playHand(ph.imitate)
+				if err != nil {
+					return nil, err
+				}
+				batch = append(batch, s...)
+			}
+			stats := t.update(batch, ph.imitate)
+			before := done
+			done += n
+			if cfg.EvalEvery > 0 && done/cfg.EvalEvery > before/cfg.EvalEvery || done == ph.hands {
+				fmt.Fprintf(log, "%s %d/%d: loss %.3f", ph.name, done, ph.hands, stats)
+				if cfg.EvalEvery > 0 {
+					margin, err := Evaluate(ctx, net, cfg.EvalDeals, cfg.Seed)
+					if err != nil {
+						return nil, err
+					}
+					fmt.Fprintf(log, ", %+.1f points per deal vs heuristic", margin)
+				}
+				fmt.Fprintln(log)
+			}
+		}
+	}
+	return net, nil
+}
+
+type trainer struct {
+	cfg   Config
+	net   *nn.MLP
+	rng   *rand.Rand
+	opt   *nn.Adam
+	grads *nn.Grads
+}
+
+// recorder plays for one seat and records its card decisions. When imitating
+// it plays like HeuristicBot; otherwise it samples from the network.
+type recorder struct {
+	player.NeuralBot
+	imitate bool
+	samples *[]sample
+}
+
+func (r *recorder) PlayCard(v engine.PlayerView) (engine.Card, bool, error) {
+	s := sample{x: player.EncodeView(v), seat: v.Seat}
+	for _, c := range v.LegalPlays {
+		s.legal = append(s.legal, player.CardIndex(c))
+	}
+	var c engine.Card
+	var marriage bool
+	if r.imitate {
+		c, marriage, _ = r.HeuristicBot.PlayCard(v)
+	} else {
+		c, marriage, _ = r.NeuralBot.PlayCard(v)
+	}
+	s.action = player.CardIndex(c)
+	*r.samples = append(*r.samples, s)
+	return c, marriage, nil
+}
+
+// playHand plays one freshly dealt hand and returns its decisions with rewards.
+func (t *trainer) playHand(imitate bool) ([]sample, error) {
+	players := []engine.PlayerID{"P1", "P2"}
+	g := engine.NewGame(engine.GameParams{}, players[t.rng.Intn(2)], players, nil)
+	if err := match.Deal(g, t.rng); err != nil {
+		return nil, err
+	}
+	var samples []sample
+	bots := map[engine.PlayerID]player.Player{}
+	for _, p := range players {
+		bots[p] = &recorder{
+			NeuralBot: player.NeuralBot{Net: t.net, Rand: t.rng, Explore: true},
+			imitate:   imitate,
+			samples:   &samples,
+		}
+	}
+	if err := match.PlayHand(g, bots); err != nil {
+		return nil, err
+	}
+	for i := range samples {
+		samples[i].reward = handReward(g, samples[i].seat)
+	}
+	return samples, nil
+}
+
+// handReward is the seat's score swing against its opponent, scaled to [-1, 1].
+func handReward(g *engine.GameState, p engine.PlayerID) float64 {
+	diff := 0
+	for q, v := range g.Scores.Cumulative {
+		if q == p {
+			diff += v
+		} else {
+			diff -= v
+		}
+	}
+	return math.Max(-1, math.Min(1, float64(diff)/300))
+}
+
+// update takes one optimiser step on batch and returns the mean loss. When
+// imitating the policy target is the recorded action; otherwise it is
+// weighted by the advantage of the outcome over the value estimate.
+func (t *trainer) update(batch []sample, imitate bool) float64 {
+	t.grads.Zero()
+	total := 0.0
+	for _, s := range batch {
+		tr := t.net.Forward(s.x)
+		out := tr.Output()
+		probs := softmax(out, s.legal)
+		value := math.Tanh(out[24])
+		d := make([]float64, player.PolicySize)
+
+		weight := 1.0
+		if !imitate {
+			weight = s.reward - value
+		}
+		total -= weight * math.Log(probs[s.action]+1e-12)
+		for _, i := range s.legal {
+			d[i] = weight * probs[i]
+		}
+		d[s.action] -= weight
+		if !imitate && t.cfg.Entropy > 0 {
+			h := 0.0
+			for _, i := range s.legal {
+				h -= probs[i] * math.Log(probs[i]+1e-12)
+			}
+			for _, i := range s.legal {
+				d[i] += t.cfg.Entropy * probs[i] * (math.Log(probs[i]+1e-12) + h)
+			}
+		}
+		diff := value - s.reward
+		total += t.cfg.ValueWeight * diff * diff
+		d[24] = 2 * t.cfg.ValueWeight * diff * (1 - value*value)
+		t.net.Backward(tr, d, t.grads)
+	}
+	t.opt.Step(t.net, t.grads, len(batch))
+	return total / float64(len(batch))
+}
+
+func softmax(out []float64, legal []int) []float64 {
+	probs := make([]float64, 24)
+	max := math.Inf(-1)
+	for _, i := range legal {
+		max = math.Max(max, out[i])
+	}
+	sum := 0.0
+	for _, i := range legal {
+		probs[i] = math.Exp(out[i] - max)
+		sum += probs[i]
+	}
+	for _, i := range legal {
+		probs[i] /= sum
+	}
+	return probs
+}
+
+// Evaluate plays deals duplicate deals of a greedy NeuralBot against
+// HeuristicBot and returns the mean points per deal in the network's favour.
+func Evaluate(ctx context.Context, net *nn.MLP, deals int, seed int64) (float64, error) {
+	report, err := tournament.Run(ctx, tournament.Config{
+		Entrants: []tournament.Entrant{
+			{Name: "neural", New: func(int64) player.Player { return &player.NeuralBot{Net: net} }},
+			{Name: "heuristic", New: func(int64) player.Player { return player.NewHeuristicBot() }},
+		},
+		Games:     deals,
+		Seed:      seed,
+		Duplicate: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(report.Pairings) == 0 {
+		return 0, fmt.Errorf("no duplicate deal finished without a forfeit (%d offences)", len(report.Offences))
+	}
+	p := report.Pairings[0]
+	if p.Players[0] != "neural" {
+		return -p.Margin, nil
+	}
+	return p.Margin, nil
+}
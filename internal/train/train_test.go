@@ -0,0 +1,77 @@
+package train
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/nn"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+func TestTrainedModelPlaysThroughRegistry(t *testing.T) {
+	net, err := Train(context.Background(), Config{
+		Hidden:         []int{16},
+		ImitationHands: 32,
+		SelfPlayHands:  32,
+		Seed:           1,
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "neural.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := net.Save(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	bot, err := player.NewFromSpec("neural:model="+path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := []engine.PlayerID{"P1", "P2"}
+	for seed := int64(1); seed <= 5; seed++ {
+		g := engine.NewGame(engine.GameParams{}, "P1", players, nil)
+		if err := match.Deal(g, rand.New(rand.NewSource(seed))); err != nil {
+			t.Fatal(err)
+		}
+		if err := match.PlayHand(g, map[engine.PlayerID]player.Player{"P1": bot, "P2": player.NewHeuristicBot()}); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+	}
+	if _, err := player.NewFromSpec("neural", 1); err == nil {
+		t.Fatal("expected an error for a neural bot without a model")
+	}
+}
+
+func TestImitationLowersLoss(t *testing.T) {
+	tr := &trainer{cfg: Config{ValueWeight: 0.5}, rng: rand.New(rand.NewSource(3))}
+	net, _ := Train(context.Background(), Config{Hidden: []int{32}, ImitationHands: 1, SelfPlayHands: 1, Seed: 3}, io.Discard)
+	tr.net, tr.grads = net, net.NewGrads()
+	tr.opt = nn.NewAdam(1e-2)
+	var batch []sample
+	for range 20 {
+		s, err := tr.playHand(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch = append(batch, s...)
+	}
+	first := tr.update(batch, true)
+	var last float64
+	for range 50 {
+		last = tr.update(batch, true)
+	}
+	if last >= first {
+		t.Fatalf("loss went from %.3f to %.3f", first, last)
+	}
+}
@@ -0,0 +1,341 @@
+// Package tui lets people play at the terminal. A Human is a player.Player
+// whose decisions are typed in: it draws the table before every decision
+// and reads commands line by line, so it works in any terminal and with
+// scripted input.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/hint"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// ErrQuit is returned from a decision when the person quits the game.
+var ErrQuit = errors.New("quit")
+
+// maxLog is the number of recent actions shown under the table.
+const maxLog = 8
+
+// Screen is the terminal shared by the Humans at a table.
+type Screen struct {
+	In  *bufio.Reader
+	Out io.Writer
+	// Clear redraws the whole screen with ANSI escapes instead of
+	// scrolling.
+	Clear bool
+	// HotSeat hides the table behind a pass-the-device screen whenever a
+	// different Human is to act, so people sharing the terminal do not see
+	// each other's cards.
+	HotSeat bool
+	// Hand, if set, numbers the hand being played in the header.
+	Hand int
+
+	// viewer is the Human whose hand is on screen.
+	viewer *Human
+	// ended is set once the result of the hand is shown.
+	ended bool
+}
+
+// NewScreen returns a Screen reading from in and drawing to out.
+func NewScreen(in io.Reader, out io.Writer) *Screen {
+	return &Screen{In: bufio.NewReader(in), Out: out}
+}
+
+// Pause waits for Enter.
+func (s *Screen) Pause(prompt string) error {
+	fmt.Fprintf(s.Out, "%s [Enter] ", prompt)
+	_, err := s.readLine()
+	return err
+}
+
+// readLine returns the next trimmed input line; the end of input quits.
+func (s *Screen) readLine() (string, error) {
+	line, err := s.In.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return "", ErrQuit
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// show hands the screen to h, asking for the device to be passed first if
+// someone else's cards were on it.
+func (s *Screen) show(h *Human) error {
+	if s.HotSeat && s.viewer != h {
+		s.viewer = nil
+		if s.Clear {
+			fmt.Fprint(s.Out, clearScreen)
+		} else {
+			fmt.Fprint(s.Out, strings.Repeat("\n", 40))
+		}
+		if err := s.Pause(fmt.Sprintf("Pass the device to %s.", h.Name())); err != nil {
+			return err
+		}
+	}
+	s.viewer = h
+	return nil
+}
+
+// Human is a player.Player reading its decisions from a Screen.
+type Human struct {
+	PlayerName string
+	Screen     *Screen
+	// Hint is the spec of the bot answering the hint command (default
+	// "ismcts:easy"); "none" disables hints.
+	Hint string
+
+	log []string
+}
+
+// NewHuman returns a Human named name playing on screen.
+func NewHuman(name string, screen *Screen) *Human {
+	return &Human{PlayerName: name, Screen: screen}
+}
+
+func (h *Human) Name() string {
+	if h.PlayerName == "" {
+		return "You"
+	}
+	return h.PlayerName
+}
+
+func (h *Human) OnHandStart(engine.PlayerView) {
+	h.Screen.ended = false
+	h.log = nil
+}
+
+func (h *Human) OnEvent(e engine.Event) {
+	a := e.Action
+	switch {
+	case a.Type == engine.ActionDeal:
+		return
+	case a.Type == engine.ActionDiscard && a.Cards == nil:
+		h.note(fmt.Sprintf("%s discards two cards", a.Player))
+	default:
+		h.note(fmt.Sprintf("%s: %v", a.Player, a))
+	}
+	if e.Trick != nil {
+		w := e.Trick.Plays[e.Trick.WinningPlayIndex]
+		h.note(fmt.Sprintf("%s wins %s (%d points)", w.Player, formatTrick(*e.Trick), trickPoints(*e.Trick)))
+	}
+}
+
+// OnHandEnd shows the result; Humans sharing a screen show it once.
+func (h *Human) OnHandEnd(view engine.PlayerView) {
+	if h.Screen.ended {
+		return
+	}
+	h.Screen.ended = true
+	h.render(view)
+	fmt.Fprintln(h.Screen.Out, handResult(view))
+}
+
+func (h *Human) note(s string) {
+	h.log = append(h.log, s)
+	if len(h.log) > maxLog {
+		h.log = h.log[len(h.log)-maxLog:]
+	}
+}
+
+func (h *Human) MakeBidDecision(view engine.PlayerView) (int, error) {
+	for {
+		next := 0
+		if len(view.LegalBids) > 1 {
+			next = view.LegalBids[1]
+		}
+		line, err := h.ask(view, fmt.Sprintf("Bid %d or more, p to pass", next))
+		if err != nil {
+			return 0, err
+		}
+		if line == "" {
+			continue
+		}
+		bid, err := parseBid(line, view)
+		if err == nil {
+			return bid, nil
+		}
+		h.note(err.Error())
+	}
+}
+
+func (h *Human) ChooseMusik(view engine.PlayerView) (int, error) {
+	for {
+		line, err := h.ask(view, fmt.Sprintf("Take musik 1-%d", view.MusikCount))
+		if err != nil {
+			return 0, err
+		}
+		if line == "" {
+			continue
+		}
+		i, err := strconv.Atoi(line)
+		if err == nil && i >= 1 && i <= view.MusikCount {
+			return i - 1, nil
+		}
+		h.note(fmt.Sprintf("no musik %q", line))
+	}
+}
+
+func (h *Human) ChooseDiscardCards(view engine.PlayerView) ([]engine.Card, error) {
+	n := len(view.Hand) - view.Params.HandCards
+	for {
+		line, err := h.ask(view, fmt.Sprintf("Discard %d cards, e.g. 1 5 or 9S JD", n))
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+		cards, err := parseDiscards(line, SortHand(view.Hand), n)
+		if err == nil {
+			return cards, nil
+		}
+		h.note(err.Error())
+	}
+}
+
+func (h *Human) PlayCard(view engine.PlayerView) (engine.Card, bool, error) {
+	for {
+		line, err := h.ask(view, "Play a card, + to announce a marriage, e.g. 4 or KH+")
+		if err != nil {
+			return engine.Card{}, false, err
+		}
+		if line == "" {
+			continue
+		}
+		card, marriage, err := parsePlay(line, view)
+		if err == nil {
+			return card, marriage, nil
+		}
+		h.note(err.Error())
+	}
+}
+
+// ask draws the table and reads the answer to prompt, handling the
+// commands available at every prompt. It returns an empty line when the
+// prompt should be repeated.
+func (h *Human) ask(view engine.PlayerView, prompt string) (string, error) {
+	if err := h.Screen.show(h); err != nil {
+		return "", err
+	}
+	h.render(view)
+	fmt.Fprintf(h.Screen.Out, "%s (h: hint, ?: help, q: quit)> ", prompt)
+	line, err := h.Screen.readLine()
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(line) {
+	case "q", "quit":
+		return "", ErrQuit
+	case "?", "help":
+		h.note(help)
+		return "", nil
+	case "h", "hint":
+		h.note(h.hint(view))
+		return "", nil
+	}
+	return line, nil
+}
+
+const help = `commands: a number or card code picks a card (by the numbers shown
+  in your hand); bids are numbers, p passes; + after a card announces the
+  marriage of its suit; h asks for a hint; q quits`
+
+// hint asks the hint bot for advice.
+func (h *Human) hint(view engine.PlayerView) string {
+	spec := h.Hint
+	if spec == "" {
+		spec = "ismcts:easy"
+	}
+	if spec == "none" {
+		return "hints are disabled"
+	}
+	fmt.Fprintln(h.Screen.Out, "thinking...")
+	adv, err := hint.Advise(context.Background(), view, hint.Options{Bot: spec, Samples: 20})
+	if err != nil {
+		return "no hint: " + err.Error()
+	}
+	return "hint: " + adv.Explanation
+}
+
+// parseBid reads "p", "pass" or a bid value.
+func parseBid(s string, view engine.PlayerView) (int, error) {
+	if strings.EqualFold(s, "p") || strings.EqualFold(s, "pass") {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("not a bid: %q", s)
+	}
+	if v == 0 || len(view.LegalBids) > 1 && v >= view.LegalBids[1] {
+		return v, nil
+	}
+	return 0, fmt.Errorf("cannot bid %d", v)
+}
+
+// parseCard reads a card by its number in the sorted hand or by its code.
+func parseCard(s string, hand []engine.Card) (engine.Card, error) {
+	if i, err := strconv.Atoi(s); err == nil {
+		if i < 1 || i > len(hand) {
+			return engine.Card{}, fmt.Errorf("no card %d", i)
+		}
+		return hand[i-1], nil
+	}
+	c, err := engine.ParseCard(s)
+	if err != nil {
+		return engine.Card{}, err
+	}
+	if !slices.Contains(hand, c) {
+		return engine.Card{}, fmt.Errorf("%s is not in your hand", c.Code())
+	}
+	return c, nil
+}
+
+func parseDiscards(s string, hand []engine.Card, n int) ([]engine.Card, error) {
+	fields := strings.Fields(s)
+	if len(fields) != n {
+		return nil, fmt.Errorf("discard exactly %d cards", n)
+	}
+	var out []engine.Card
+	for _, f := range fields {
+		c, err := parseCard(f, hand)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(out, c) {
+			return nil, fmt.Errorf("%s given twice", c.Code())
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// parsePlay reads a card, optionally followed by "+" to announce the
+// marriage of its suit.
+func parsePlay(s string, view engine.PlayerView) (engine.Card, bool, error) {
+	code, marriage := strings.CutSuffix(s, "+")
+	c, err := parseCard(strings.TrimSpace(code), SortHand(view.Hand))
+	if err != nil {
+		return engine.Card{}, false, err
+	}
+	if !slices.Contains(view.LegalPlays, c) {
+		return engine.Card{}, false, fmt.Errorf("%s cannot be played now", c.Code())
+	}
+	if marriage && !view.MarriageAllowed() {
+		return engine.Card{}, false, fmt.Errorf("no marriage may be announced on the first trick")
+	}
+	if marriage && !player.AnnouncesMarriage(view, c) {
+		return engine.Card{}, false, fmt.Errorf("%s announces no marriage", c.Code())
+	}
+	return c, marriage, nil
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// newGame deals P2 a heart marriage, and 9H JH in the first musik.
+func newGame(t *testing.T) *engine.GameState {
+	t.Helper()
+	deck, err := engine.ParseCards("AH 10H QD KD 10D AD QC KC 10C KS " +
+		"KH QH AS 10S AC 9D JD 9C JC 9S " +
+		"9H JH JS QS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := engine.NewGame(engine.GameParams{}, "P1", []engine.PlayerID{"P1", "P2"}, nil)
+	if err := match.DealDeck(g, deck); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestHumanPlaysAHand(t *testing.T) {
+	g := newGame(t)
+	// bid, then keep cycling through answers: the ones that do not fit the
+	// prompt are rejected and asked again, so every decision gets made
+	script := "?\n110\n" + strings.Repeat("p\n1\n1 2\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", 30)
+	var out strings.Builder
+	human := NewHuman("P2", NewScreen(strings.NewReader(script), &out))
+	bots := map[engine.PlayerID]player.Player{"P1": player.NewHeuristicBot(), "P2": human}
+	if err := match.PlayHand(g, bots); err != nil {
+		t.Fatal(err)
+	}
+	if g.Phase != engine.PhaseHandEnd {
+		t.Fatalf("hand stopped in %v phase", g.Phase)
+	}
+	if g.Auction.Bids[1] != (engine.AuctionBid{Player: "P2", Value: 110}) {
+		t.Errorf("first bid %+v, want P2 110", g.Auction.Bids[1])
+	}
+	for _, want := range []string{"commands:", "P2, your hand:", "Hand over: "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+}
+
+func TestHumanQuits(t *testing.T) {
+	for _, script := range []string{"q\n", ""} {
+		human := NewHuman("P2", NewScreen(strings.NewReader(script), &strings.Builder{}))
+		bots := map[engine.PlayerID]player.Player{"P1": player.NewHeuristicBot(), "P2": human}
+		if err := match.PlayHand(newGame(t), bots); !errors.Is(err, ErrQuit) {
+			t.Errorf("script %q: got %v, want ErrQuit", script, err)
+		}
+	}
+}
+
+func TestParsePlay(t *testing.T) {
+	g := newGame(t)
+	for _, a := range []engine.Action{
+		{Type: engine.ActionBid, Player: "P2", Value: 110},
+		{Type: engine.ActionBid, Player: "P1", Value: 0},
+		{Type: engine.ActionChooseMusik, Player: "P2", Index: 0},
+		{Type: engine.ActionDiscard, Player: "P2", Cards: []engine.Card{{Suit: engine.Spades, Rank: engine.Nine}, {Suit: engine.Clubs, Rank: engine.Nine}}},
+	} {
+		if err := g.Apply(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	view := g.View("P2")
+	kh := engine.Card{Suit: engine.Hearts, Rank: engine.King}
+	for _, tc := range []struct {
+		in       string
+		card     engine.Card
+		marriage bool
+		err      bool
+	}{
+		{in: "KH+", card: kh, marriage: true},
+		{in: "kh", card: kh},
+		{in: "1", card: engine.Card{Suit: engine.Spades, Rank: engine.Ace}},
+		{in: "AS+", err: true},
+		{in: "QS", err: true},
+		{in: "11", err: true},
+		{in: "x", err: true},
+	} {
+		card, marriage, err := parsePlay(tc.in, view)
+		if (err != nil) != tc.err {
+			t.Errorf("%q: error %v", tc.in, err)
+			continue
+		}
+		if !tc.err && (card != tc.card || marriage != tc.marriage) {
+			t.Errorf("%q: got %s marriage=%v", tc.in, card.Code(), marriage)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// SortHand returns the cards grouped by suit, highest first within a suit;
+// the numbers a Human types refer to this order.
+func SortHand(hand []engine.Card) []engine.Card {
+	out := slices.Clone(hand)
+	slices.SortFunc(out, func(a, b engine.Card) int {
+		if a.Suit != b.Suit {
+			return int(a.Suit) - int(b.Suit)
+		}
+		// ranks are declared from the weakest
+		return int(b.Rank) - int(a.Rank)
+	})
+	return out
+}
+
+// suitSymbol returns the suit's symbol without the emoji variation
+// selector, which many terminals render wider than one column.
+func suitSymbol(s engine.Suit) string {
+	return strings.TrimSuffix(s.String(), "\uFE0F")
+}
+
+func cardLabel(c engine.Card) string {
+	return c.Rank.String() + suitSymbol(c.Suit)
+}
+
+func formatTrick(t engine.Trick) string {
+	var plays []string
+	for _, p := range t.Plays {
+		s := cardLabel(p.Card)
+		if p.AnnouncedMarriage != nil {
+			s += fmt.Sprintf(" (marriage %d)", engine.MarriageValue(*p.AnnouncedMarriage))
+		}
+		plays = append(plays, fmt.Sprintf("%s %s", p.Player, s))
+	}
+	return strings.Join(plays, ", ")
+}
+
+func trickPoints(t engine.Trick) int {
+	n := 0
+	for _, p := range t.Plays {
+		n += engine.PointsFor(p.Card.Rank)
+	}
+	return n
+}
+
+// scores formats a score per player in seating order.
+func scores(view engine.PlayerView, points map[engine.PlayerID]int) string {
+	var out []string
+	for _, p := range view.Params.Players {
+		out = append(out, fmt.Sprintf("%s %d", p, points[p]))
+	}
+	return strings.Join(out, " · ")
+}
+
+// render draws the table as view's seat sees it.
+func (h *Human) render(view engine.PlayerView) {
+	var b strings.Builder
+	if h.Screen.Clear {
+		b.WriteString(clearScreen)
+	} else {
+		b.WriteString("\n")
+	}
+	if h.Screen.Hand > 0 {
+		fmt.Fprintf(&b, "Hand %d · ", h.Screen.Hand)
+	}
+	fmt.Fprintf(&b, "%s · playing to %d\n", scores(view, view.Cumulative), view.Params.MaxGamePoints)
+
+	fmt.Fprintf(&b, "Dealer: %s", view.Dealer)
+	if view.Declarer != nil {
+		fmt.Fprintf(&b, " · Contract: %s %d", *view.Declarer, view.HighestBid)
+	}
+	if view.Trump != nil {
+		fmt.Fprintf(&b, " · Trump: %s", suitSymbol(*view.Trump))
+	}
+	b.WriteString("\n")
+
+	var bids []string
+	for _, bid := range view.Bids {
+		if bid.Pass {
+			bids = append(bids, fmt.Sprintf("%s pass", bid.Player))
+		} else {
+			bids = append(bids, fmt.Sprintf("%s %d", bid.Player, bid.Value))
+		}
+	}
+	fmt.Fprintf(&b, "Bids: %s\n", strings.Join(bids, ", "))
+
+	if view.Phase >= engine.PhasePlay {
+		fmt.Fprintf(&b, "Points this hand: %s\n", scores(view, view.DealPoints))
+		for _, m := range view.Marriages {
+			fmt.Fprintf(&b, "Marriage: %s %s %d\n", m.Player, suitSymbol(m.Suit), engine.MarriageValue(m.Suit))
+		}
+		if n := len(view.CompletedTricks); n > 0 {
+			t := view.CompletedTricks[n-1]
+			fmt.Fprintf(&b, "Last trick: %s, won by %s\n", formatTrick(t), t.Plays[t.WinningPlayIndex].Player)
+		}
+	}
+	if view.Phase == engine.PhasePlay {
+		fmt.Fprintf(&b, "Trick %d: %s\n", len(view.CompletedTricks)+1, formatTrick(view.CurrentTrick))
+	}
+	if len(view.OwnDiscards) > 0 {
+		fmt.Fprintf(&b, "Your discards: %s\n", labels(view.OwnDiscards))
+	}
+
+	b.WriteString("\n")
+	for _, line := range h.log {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	fmt.Fprintf(&b, "\n%s, your hand:\n", view.Seat)
+	hand := SortHand(view.Hand)
+	for i, c := range hand {
+		if i > 0 && c.Suit != hand[i-1].Suit {
+			b.WriteString("   ")
+		}
+		fmt.Fprintf(&b, " %d:%s", i+1, cardLabel(c))
+	}
+	b.WriteString("\n")
+	fmt.Fprint(h.Screen.Out, b.String())
+}
+
+func labels(cards []engine.Card) string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = cardLabel(c)
+	}
+	return strings.Join(out, " ")
+}
+
+// handResult describes the scored hand.
+func handResult(view engine.PlayerView) string {
+	if view.Declarer == nil {
+		return "Hand over."
+	}
+	d := *view.Declarer
+	result := "makes it"
+	if view.DealPoints[d] < view.HighestBid {
+		result = "goes down"
+	}
+	return fmt.Sprintf("Hand over: %s %s with %d of %d. Scores: %s",
+		d, result, view.DealPoints[d], view.HighestBid, scores(view, view.Cumulative))
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/handrecord"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+// Save is a match in progress, written by tys play -save and continued by
+// tys play -resume.
+type Save struct {
+	// Seats holds a seat spec per player in seating order (see NewSeats).
+	Seats   []string          `json:"seats"`
+	Players []engine.PlayerID `json:"players"`
+	Params  engine.GameParams `json:"params"`
+	// Seed seeds the deals: hand n is shuffled by a source seeded with
+	// Seed+n, so a resumed match deals as the original would have.
+	Seed int64 `json:"seed"`
+	// Hands holds every hand dealt so far; the last may be unfinished.
+	Hands []*handrecord.Record `json:"hands"`
+}
+
+// ReadSave reads a Save written by Write.
+func ReadSave(path string) (*Save, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Save
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(s.Seats) != 2 || len(s.Players) != 2 {
+		return nil, fmt.Errorf("%s: %d seats and %d players, want 2", path, len(s.Seats), len(s.Players))
+	}
+	return &s, nil
+}
+
+// Write writes the save to path, replacing it only once fully written.
+func (s *Save) Write(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tys-save-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Game returns the game to play: the last hand replayed up to where it
+// stopped if it is unfinished, otherwise the next hand, dealt in turn and
+// appended to Hands.
+func (s *Save) Game() (*engine.GameState, error) {
+	var cumulative map[engine.PlayerID]int
+	if n := len(s.Hands); n > 0 {
+		last := s.Hands[n-1]
+		if last.Cumulative == nil {
+			g, err := last.Replay()
+			if err != nil {
+				return nil, fmt.Errorf("hand %d: %w", n, err)
+			}
+			return g, nil
+		}
+		cumulative = last.Cumulative
+	}
+	n := len(s.Hands)
+	g := engine.NewGame(s.Params, s.Players[n%len(s.Players)], s.Players, cumulative)
+	seed := s.Seed + int64(n)
+	if err := match.Deal(g, rand.New(rand.NewSource(seed))); err != nil {
+		return nil, fmt.Errorf("deal: %w", err)
+	}
+	rec := handrecord.New(g)
+	rec.Seed = &seed
+	s.Hands = append(s.Hands, rec)
+	return g, nil
+}
+
+// Winner returns the winner once a player has reached the target score.
+func (s *Save) Winner() (engine.PlayerID, bool) {
+	if len(s.Hands) == 0 || s.Hands[len(s.Hands)-1].Cumulative == nil {
+		return "", false
+	}
+	g := engine.NewGame(s.Params, s.Players[0], s.Players, s.Hands[len(s.Hands)-1].Cumulative)
+	over, winner := g.IsWinningGame()
+	return winner, over
+}
+
+// NewSeats creates a player per seat spec: "human" or "human:Name" for a
+// person at the screen, anything else a bot spec for player.NewFromSpec.
+// Bots are seeded from seed. Several humans share the screen in hot-seat
+// mode. The engine seats exactly two players.
+func NewSeats(specs []string, screen *Screen, seed int64) ([]player.Player, error) {
+	if len(specs) != 2 {
+		return nil, fmt.Errorf("%d seats, want 2", len(specs))
+	}
+	var out []player.Player
+	humans := 0
+	for i, spec := range specs {
+		if name, ok := strings.CutPrefix(spec, "human"); ok && (name == "" || name[0] == ':') {
+			h := NewHuman(strings.TrimPrefix(name, ":"), screen)
+			if h.PlayerName == "" {
+				h.PlayerName = fmt.Sprintf("Player %d", i+1)
+			}
+			out = append(out, h)
+			humans++
+			continue
+		}
+		bot, err := player.NewFromSpec(spec, seed+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, bot)
+	}
+	screen.HotSeat = humans > 1
+	return out, nil
+}
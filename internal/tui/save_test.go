@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZygmuntJakub/1000/internal/engine"
+	"github.com/ZygmuntJakub/1000/internal/match"
+	"github.com/ZygmuntJakub/1000/internal/player"
+)
+
+func TestSaveResumesUnfinishedHand(t *testing.T) {
+	s := &Save{Seats: []string{"heuristic", "heuristic"}, Players: []engine.PlayerID{"P1", "P2"}, Seed: 3}
+	g, err := s.Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := player.NewHeuristicBot()
+	for g.Phase != engine.PhasePlay || len(g.Play.CompletedTricks) < 3 || len(g.Play.CurrentTrick.Plays) == 0 {
+		a, err := match.Decide(bot, g.View(g.ActingPlayer()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Apply(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Hands[0].Update(g)
+	path := filepath.Join(t.TempDir(), "match.json")
+	if err := s.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := loaded.Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range s.Players {
+		want, _ := json.Marshal(g.View(p))
+		got, _ := json.Marshal(resumed.View(p))
+		if string(got) != string(want) {
+			t.Fatalf("%s sees\n%s\nafter resuming, want\n%s", p, got, want)
+		}
+	}
+
+	if err := match.PlayHand(resumed, map[engine.PlayerID]player.Player{"P1": bot, "P2": bot}); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Hands[0].Update(resumed)
+	next, err := loaded.Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Hands) != 2 || next.Dealer != "P2" || next.Phase != engine.PhaseAuction {
+		t.Fatalf("next hand: %d hands, dealer %s, %v phase", len(loaded.Hands), next.Dealer, next.Phase)
+	}
+	for _, p := range s.Players {
+		if next.Scores.Cumulative[p] != resumed.Scores.Cumulative[p] {
+			t.Errorf("%s starts the next hand on %d, want %d", p, next.Scores.Cumulative[p], resumed.Scores.Cumulative[p])
+		}
+	}
+}
+
+func TestHotSeatPassesTheDevice(t *testing.T) {
+	script := strings.Repeat("p\n1\n1 2\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", 60)
+	var out strings.Builder
+	screen := NewScreen(strings.NewReader(script), &out)
+	seats, err := NewSeats([]string{"human:P1", "human:P2"}, screen, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !screen.HotSeat {
+		t.Fatal("two humans do not share the screen in hot-seat mode")
+	}
+	g := newGame(t)
+	if err := match.PlayHand(g, map[engine.PlayerID]player.Player{"P1": seats[0], "P2": seats[1]}); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	for _, p := range []string{"P1", "P2"} {
+		if !strings.Contains(text, "Pass the device to "+p) {
+			t.Errorf("never passed the device to %s", p)
+		}
+	}
+	if n := strings.Count(text, "Hand over: "); n != 1 {
+		t.Errorf("result shown %d times", n)
+	}
+
+	seats, err = NewSeats([]string{"human", "random:seed=3"}, screen, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if screen.HotSeat || seats[0].Name() != "Player 1" || seats[1].Name() != "RandomBot" {
+		t.Errorf("got hot seat %v, seats %s and %s", screen.HotSeat, seats[0].Name(), seats[1].Name())
+	}
+	if _, err := NewSeats([]string{"human", "heuristic", "random"}, screen, 1); err == nil {
+		t.Error("seated three players")
+	}
+}